@@ -0,0 +1,129 @@
+// client/batch.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultConcurrency is the default number of RPCs a fan-out helper keeps
+// in flight.
+const DefaultConcurrency = 8
+
+// maxBatchAddresses caps the number of addresses sent in one batch request.
+const maxBatchAddresses = 100
+
+// BalanceResult is the outcome of one address in GetBalances. Exactly one
+// of Info and Err is set.
+type BalanceResult struct {
+	Address string
+	Info    *BalanceInfo
+	Err     error
+}
+
+// GetBalances looks up many addresses at once. Results are returned in the
+// order of addrs; a failing address does not fail the batch. Nodes that
+// offer a batch endpoint are queried in chunks, otherwise GetBalance is
+// fanned out over at most WithConcurrency workers.
+func (c *OctraClient) GetBalances(ctx context.Context, addrs []string) []BalanceResult {
+	results, _ := traced(ctx, c, "GetBalances", func(ctx context.Context) ([]BalanceResult, error) {
+		ctx = withOperation(ctx, "GetBalances")
+		results := make([]BalanceResult, len(addrs))
+		for i, addr := range addrs {
+			results[i].Address = addr
+		}
+		if info, err := c.NodeInfo(ctx); err == nil && info.Features[FeatureBatchBalance] {
+			c.batchBalances(ctx, results)
+		} else {
+			c.forEach(ctx, len(results), func(ctx context.Context, i int) {
+				results[i].Info, results[i].Err = c.GetBalance(ctx, results[i].Address)
+			})
+		}
+		var errs []error
+		for i := range results {
+			if results[i].Info == nil && results[i].Err == nil {
+				results[i].Err = ctx.Err()
+			}
+			errs = append(errs, results[i].Err)
+		}
+		return results, errors.Join(errs...)
+	})
+	return results
+}
+
+func (c *OctraClient) batchBalances(ctx context.Context, results []BalanceResult) {
+	var chunks [][]BalanceResult
+	for start := 0; start < len(results); start += maxBatchAddresses {
+		chunks = append(chunks, results[start:min(start+maxBatchAddresses, len(results))])
+	}
+	c.forEach(ctx, len(chunks), func(ctx context.Context, i int) {
+		c.batchChunk(ctx, chunks[i])
+	})
+}
+
+func (c *OctraClient) batchChunk(ctx context.Context, chunk []BalanceResult) {
+	addrs := make([]string, len(chunk))
+	for i, r := range chunk {
+		addrs[i] = r.Address
+	}
+	ctx, cancel := c.withDeadline(ctx, "GetBalances")
+	defer cancel()
+	data, err := c.doRequest(ctx, "GetBalances", "POST", "/balances", map[string]interface{}{"addresses": addrs})
+	var res struct {
+		Balances []BalanceInfo `json:"balances"`
+	}
+	if err == nil {
+		err = decodeResponse("/balances", c.adapt(data), &res)
+	}
+	if err != nil {
+		for i := range chunk {
+			chunk[i].Err = err
+		}
+		return
+	}
+	found := make(map[string]*BalanceInfo, len(res.Balances))
+	for i := range res.Balances {
+		info := &res.Balances[i]
+		found[info.Address] = info
+		if raw, err := json.Marshal(info); err == nil {
+			c.cacheSet("balance:"+info.Address, raw, c.balanceTTL)
+		}
+	}
+	for i := range chunk {
+		if info, ok := found[chunk[i].Address]; ok {
+			chunk[i].Info = info
+		} else {
+			chunk[i].Err = fmt.Errorf("%w: balance for %s missing from batch response", ErrNotFound, chunk[i].Address)
+		}
+	}
+}
+
+// forEach runs fn for 0..n-1 on at most c.concurrency goroutines. Indexes
+// not yet started when ctx is done are skipped.
+func (c *OctraClient) forEach(ctx context.Context, n int, fn func(ctx context.Context, i int)) {
+	workers := min(max(c.concurrency, 1), n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(ctx, i)
+			}
+		}()
+	}
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetBalancesFanOut(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := strings.CutPrefix(r.URL.Path, "/balance/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if addr == "octBad" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"address not found"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"address":%q,"balance_raw":"%d","nonce":1}`, addr, len(addr))
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL, WithConcurrency(3))
+	addrs := []string{"octA", "octBB", "octBad", "octCCC", "octD", "octEE", "octF"}
+	results := oc.GetBalances(context.Background(), addrs)
+
+	if len(results) != len(addrs) {
+		t.Fatalf("expected %d results, got %d", len(addrs), len(results))
+	}
+	for i, r := range results {
+		if r.Address != addrs[i] {
+			t.Errorf("result %d out of order: %s", i, r.Address)
+		}
+		if r.Address == "octBad" {
+			if !errors.Is(r.Err, ErrNotFound) || r.Info != nil {
+				t.Errorf("expected ErrNotFound for octBad, got %+v", r)
+			}
+			continue
+		}
+		if r.Err != nil || r.Info.BalanceRaw != fmt.Sprint(len(r.Address)) {
+			t.Errorf("unexpected result for %s: %+v", r.Address, r)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("expected at most 3 concurrent requests, saw %d", peak)
+	}
+}
+
+func TestGetBalancesCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, r := range oc.GetBalances(ctx, []string{"octA", "octB"}) {
+		if r.Err == nil || r.Info != nil {
+			t.Errorf("expected an error for %s after cancellation, got %+v", r.Address, r)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// client/cache.go
+package client
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache stores raw node responses. A zero ttl means the entry never expires.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+// DefaultBalanceTTL is how long balances are served from the cache.
+const DefaultBalanceTTL = 2 * time.Second
+
+// LRUCache is an in-memory Cache bounded to a fixed number of entries.
+type LRUCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{capacity: capacity, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		l.order.Remove(el)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (l *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	if el, ok := l.entries[key]; ok {
+		el.Value = &cacheEntry{key: key, value: value, expires: expires}
+		l.order.MoveToFront(el)
+		return
+	}
+	l.entries[key] = l.order.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if el, ok := l.entries[key]; ok {
+		l.order.Remove(el)
+		delete(l.entries, key)
+	}
+}
+
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// FileCache is a Cache persisted as one file per entry in Dir, so confirmed
+// transactions survive process restarts.
+type FileCache struct {
+	Dir string
+}
+
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileCache{Dir: dir}, nil
+}
+
+type fileEntry struct {
+	Expires time.Time       `json:"expires,omitempty"`
+	Value   json.RawMessage `json:"value"`
+}
+
+func (f *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(f.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (f *FileCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry fileEntry
+	if json.Unmarshal(data, &entry) != nil {
+		return nil, false
+	}
+	if !entry.Expires.IsZero() && time.Now().After(entry.Expires) {
+		os.Remove(f.path(key))
+		return nil, false
+	}
+	return entry.Value, true
+}
+
+func (f *FileCache) Set(key string, value []byte, ttl time.Duration) {
+	entry := fileEntry{Value: value}
+	if ttl > 0 {
+		entry.Expires = time.Now().Add(ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(f.Dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	os.Rename(tmp.Name(), f.path(key))
+}
+
+func (f *FileCache) Delete(key string) {
+	os.Remove(f.path(key))
+}
+
+func (c *OctraClient) cacheGet(key string) ([]byte, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	return c.cache.Get(key)
+}
+
+func (c *OctraClient) cacheSet(key string, data []byte, ttl time.Duration) {
+	if c.cache != nil {
+		c.cache.Set(key, data, ttl)
+	}
+}
+
+func (c *OctraClient) cacheDelete(key string) {
+	if c.cache != nil {
+		c.cache.Delete(key)
+	}
+}
+
+// flightGroup coalesces concurrent calls with the same key into one.
+type flightGroup struct {
+	// timeout bounds a shared call, which outlives the caller that started
+	// it.
+	timeout time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// do runs fn once for concurrent callers with the same key. fn runs on a
+// context detached from the first caller's cancellation, so one caller
+// giving up does not fail the others; each caller stops waiting as soon as
+// its own ctx is done.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go func() {
+			shared, cancel := context.WithoutCancel(ctx), context.CancelFunc(func() {})
+			if g.timeout > 0 {
+				shared, cancel = context.WithTimeout(shared, g.timeout)
+			}
+			call.data, call.err = fn(shared)
+			cancel()
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.data, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheAndCoalescing(t *testing.T) {
+	var balanceCalls, txCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/tx/") {
+			atomic.AddInt32(&txCalls, 1)
+			w.Write([]byte(`{"status":"confirmed","epoch":7,"parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`))
+			return
+		}
+		atomic.AddInt32(&balanceCalls, 1)
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"balance_raw":"100","nonce":2}`))
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL, WithCache(NewLRUCache(128)), WithBalanceTTL(time.Minute))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if info, err := oc.GetBalance(ctx, "octA"); err != nil || info.Nonce != 2 {
+				t.Errorf("GetBalance failed: %+v (%v)", info, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if balanceCalls != 1 {
+		t.Errorf("expected 50 concurrent lookups to produce 1 RPC, got %d", balanceCalls)
+	}
+
+	for i := 0; i < 3; i++ {
+		if tx, err := oc.GetTransaction(ctx, "h1"); err != nil || tx.Epoch != 7 {
+			t.Fatalf("GetTransaction failed: %+v (%v)", tx, err)
+		}
+	}
+	if txCalls != 1 {
+		t.Errorf("confirmed transaction should be cached, fetched %d times", txCalls)
+	}
+}
+
+func TestFileCacheExpiry(t *testing.T) {
+	fc, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileCache failed: %v", err)
+	}
+	fc.Set("tx:h1", []byte(`{"status":"confirmed"}`), 0)
+	fc.Set("balance:octA", []byte(`{"nonce":1}`), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if v, ok := fc.Get("tx:h1"); !ok || string(v) != `{"status":"confirmed"}` {
+		t.Errorf("permanent entry lost: %q %v", v, ok)
+	}
+	if _, ok := fc.Get("balance:octA"); ok {
+		t.Errorf("expired entry should not be served")
+	}
+}
+
+func TestCacheSkipsUnsettledAnswers(t *testing.T) {
+	var balanceCalls, txCalls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/tx/") {
+			if atomic.AddInt32(&txCalls, 1) == 1 {
+				w.Write([]byte(`{"status":"confirmed","parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`))
+				return
+			}
+			w.Write([]byte(`{"status":"confirmed","epoch":7,"parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`))
+			return
+		}
+		atomic.AddInt32(&balanceCalls, 1)
+		w.Write([]byte(`{"balance_raw":"100","nonce":2}`))
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL, WithCache(NewLRUCache(128)), WithBalanceTTL(0))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		oc.GetBalance(ctx, "octA")
+	}
+	if balanceCalls != 2 {
+		t.Errorf("WithBalanceTTL(0) must not cache balances, got %d RPCs for 2 lookups", balanceCalls)
+	}
+
+	// A confirmed answer without its epoch is not final.
+	for i := 0; i < 3; i++ {
+		oc.GetTransaction(ctx, "h1")
+	}
+	if txCalls != 2 {
+		t.Errorf("expected the answer without epoch to be fetched again and the next one cached, got %d RPCs", txCalls)
+	}
+}
+
+func TestCoalescedCallersKeepTheirOwnContext(t *testing.T) {
+	release := make(chan struct{})
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		w.Write([]byte(`{"balance_raw":"100","nonce":2}`))
+	}))
+	defer srv.Close()
+	oc := NewClient(srv.URL, WithRequestCoalescing())
+
+	// The caller that starts the request gives up; a second caller still
+	// gets the shared answer.
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leader := make(chan error, 1)
+	go func() {
+		_, err := oc.GetBalance(leaderCtx, "octA")
+		leader <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	follower := make(chan error, 1)
+	go func() {
+		_, err := oc.GetBalance(context.Background(), "octA")
+		follower <- err
+	}()
+
+	// A waiter with a short deadline stops at its own deadline.
+	short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := oc.GetBalance(short, "octA"); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Errorf("expected the waiter to stop at its deadline, got %v after %v", err, time.Since(start))
+	}
+
+	cancelLeader()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the leader to see its cancellation, got %v", err)
+	}
+	close(release)
+	if err := <-follower; err != nil {
+		t.Errorf("follower must not fail because the leader cancelled: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected one shared RPC, got %d", n)
+	}
+}
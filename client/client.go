@@ -25,7 +25,9 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -38,9 +40,24 @@ import (
 	"golang.org/x/crypto/scrypt"
 )
 
+// OctraClient is configured once through NewClient options and is safe for
+// concurrent use by multiple goroutines.
 type OctraClient struct {
-	BaseURL    string
-	HTTPClient *http.Client
+	baseURL      string
+	httpClient   *http.Client
+	headers      http.Header
+	retry        RetryPolicy
+	endpoints    *EndpointPool
+	interceptors []Interceptor
+	deadlines    map[string]time.Duration
+	limiter      *RateLimiter
+	cache        Cache
+	balanceTTL   time.Duration
+	flight       *flightGroup
+	probe        *probeState
+	concurrency  int
+	depth        uint64
+	pollInterval time.Duration
 }
 
 type Keystore struct {
@@ -78,13 +95,47 @@ type SignedTransaction struct {
 	Raw       string      `json:"raw,omitempty"`
 }
 
-func NewClient(url string) *OctraClient {
+func NewClient(url string, opts ...Option) *OctraClient {
+	cfg := &clientConfig{
+		timeout:      30 * time.Second,
+		headers:      make(http.Header),
+		retry:        DefaultRetryPolicy(),
+		deadlines:    make(map[string]time.Duration),
+		balanceTTL:   DefaultBalanceTTL,
+		concurrency:  DefaultConcurrency,
+		depth:        1,
+		pollInterval: DefaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	hc := cfg.buildHTTPClient()
+	var flight *flightGroup
+	if cfg.coalesce { flight = &flightGroup{timeout: hc.Timeout} }
 	return &OctraClient{
-		BaseURL:    strings.TrimSuffix(url, "/"),
-		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:      strings.TrimSuffix(url, "/"),
+		httpClient:   hc,
+		headers:      cfg.headers,
+		retry:        cfg.retry,
+		endpoints:    cfg.endpoints,
+		interceptors: cfg.interceptors,
+		deadlines:    cfg.deadlines,
+		limiter:      cfg.limiter,
+		cache:        cfg.cache,
+		balanceTTL:   cfg.balanceTTL,
+		flight:       flight,
+		probe:        &probeState{},
+		concurrency:  cfg.concurrency,
+		depth:        cfg.depth,
+		pollInterval: cfg.pollInterval,
 	}
 }
 
+func (c *OctraClient) BaseURL() string { return c.baseURL }
+
+// Endpoints returns the endpoint pool of a multi-endpoint client, or nil.
+func (c *OctraClient) Endpoints() *EndpointPool { return c.endpoints }
+
 func PublicKeyToAddress(publicKey []byte) string {
 	hash := sha256.Sum256(publicKey)
 	return "oct" + base58.Encode(hash[:])
@@ -151,7 +202,7 @@ func DecryptWallet(keystoreJSON, password string) (string, error) {
 	return base64.StdEncoding.EncodeToString(seed), nil
 }
 
-func SignTransaction(tx Transaction, privateKeyB64 string) (*SignedTransaction, error) {
+func normalizeTransaction(tx Transaction) Transaction {
 	amountFloat, _ := strconv.ParseFloat(tx.Amount, 64)
 	if tx.OU == "" {
 		if amountFloat < 1000000000 {
@@ -166,7 +217,13 @@ func SignTransaction(tx Transaction, privateKeyB64 string) (*SignedTransaction,
 		cleanTS := strconv.FormatFloat(val, 'f', -1, 64)
 		tx.Timestamp = json.Number(cleanTS)
 	}
+	return tx
+}
 
+// CanonicalPayload returns the OTX-1 bytes that are signed and hashed for tx.
+// The message is deliberately excluded.
+func CanonicalPayload(tx Transaction) ([]byte, error) {
+	tx = normalizeTransaction(tx)
 	signPayload := struct {
 		From      string      `json:"from"`
 		To        string      `json:"to_"`
@@ -182,8 +239,12 @@ func SignTransaction(tx Transaction, privateKeyB64 string) (*SignedTransaction,
 		OU:        tx.OU,
 		Timestamp: tx.Timestamp,
 	}
+	return json.Marshal(signPayload)
+}
 
-	canonicalData, err := json.Marshal(signPayload)
+func SignTransaction(tx Transaction, privateKeyB64 string) (*SignedTransaction, error) {
+	tx = normalizeTransaction(tx)
+	canonicalData, err := CanonicalPayload(tx)
 	if err != nil {
 		return nil, err
 	}
@@ -202,6 +263,18 @@ func SignTransaction(tx Transaction, privateKeyB64 string) (*SignedTransaction,
 	}, nil
 }
 
+// Hash returns the transaction hash the node assigns: the hex SHA-256 of the
+// canonical OTX-1 payload.
+func (s *SignedTransaction) Hash() string {
+	raw := []byte(s.Raw)
+	if len(raw) == 0 {
+		var err error
+		if raw, err = CanonicalPayload(s.Tx); err != nil { return "" }
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
 func (s *SignedTransaction) ToMap() map[string]interface{} {
 	m := map[string]interface{}{
 		"from":       s.Tx.From,
@@ -219,67 +292,166 @@ func (s *SignedTransaction) ToMap() map[string]interface{} {
 	return m
 }
 
-func (c *OctraClient) doRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	var bodyReader io.Reader
-	if body != nil {
-		jsonData, _ := json.Marshal(body)
-		bodyReader = bytes.NewBuffer(jsonData)
+func (c *OctraClient) doRequest(ctx context.Context, op, method, path string, body interface{}) ([]byte, error) {
+	run := func(ctx context.Context) ([]byte, error) {
+		call := &Call{Parent: methodCallFrom(ctx), Operation: operationFrom(ctx, op), Method: method, Path: path, Request: body}
+		err := c.chain(ctx, call, c.invoke)
+		return call.Response, err
+	}
+	if method == "GET" && c.flight != nil { return c.flight.do(ctx, path, run) }
+	return run(ctx)
+}
+
+// invoke performs call against the node, retrying idempotent requests.
+func (c *OctraClient) invoke(ctx context.Context, call *Call) error {
+	var payload []byte
+	if call.Request != nil {
+		jsonData, err := json.Marshal(call.Request)
+		if err != nil {
+			call.Err = fmt.Errorf("encode %s request: %w", call.Path, err)
+			return call.Err
+		}
+		payload = jsonData
+	}
+	attempts := 1
+	if call.Method == "GET" { attempts = c.retry.attempts() }
+	prior := priorAttempts(ctx)
+	for attempt := 1; ; attempt++ {
+		call.Attempts = prior + attempt
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, call.Path); err != nil {
+				call.Err = err
+				return err
+			}
+		}
+		data, err := c.doOnce(ctx, call.Method, call.Path, call.Header, payload)
+		call.Response, call.Err = data, err
+		if c.limiter != nil {
+			if errors.Is(err, ErrRateLimited) { c.limiter.Throttle(call.Path) } else if err == nil { c.limiter.Recover(call.Path) }
+		}
+		if err == nil || attempt >= attempts || !IsRetryable(err) { return err }
+		if serr := sleepContext(ctx, c.retry.Backoff(attempt, err)); serr != nil { return err }
 	}
-	req, _ := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bodyReader)
+}
+
+func (c *OctraClient) doOnce(ctx context.Context, method, path string, header http.Header, payload []byte) ([]byte, error) {
+	if c.endpoints == nil { return c.doAt(ctx, c.baseURL, method, path, header, payload) }
+	node := c.endpoints.pick()
+	if node == nil { return nil, ErrNoEndpoints }
+	start := time.Now()
+	data, err := c.doAt(ctx, node.url, method, path, header, payload)
+	c.endpoints.report(node, time.Since(start), err)
+	if err == nil && path == "/status" { c.endpoints.reportStatus(node.url, data) }
+	return data, err
+}
+
+func (c *OctraClient) doAt(ctx context.Context, baseURL, method, path string, header http.Header, payload []byte) ([]byte, error) {
+	var bodyReader io.Reader
+	if payload != nil { bodyReader = bytes.NewReader(payload) }
+	req, err := http.NewRequestWithContext(ctx, method, baseURL+path, bodyReader)
+	if err != nil { return nil, err }
+	for k, v := range c.headers { req.Header[k] = v }
+	for k, v := range header { req.Header[k] = v }
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil { return nil, err }
 	defer resp.Body.Close()
-	data, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode >= 400 { return nil, fmt.Errorf("rpc error [%d]: %s", resp.StatusCode, string(data)) }
+	data, err := io.ReadAll(resp.Body)
+	if err != nil { return nil, fmt.Errorf("read %s response: %w", path, err) }
+	if resp.StatusCode >= 400 {
+		rpcErr := newRPCError(resp.StatusCode, method, path, data)
+		rpcErr.RetryAfter = parseRetryAfter(resp.Header)
+		return nil, rpcErr
+	}
 	return data, nil
 }
 
+func decodeResponse(path string, data []byte, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
 func (c *OctraClient) GetBalance(ctx context.Context, address string) (*BalanceInfo, error) {
-	data, err := c.doRequest(ctx, "GET", "/balance/"+address, nil)
-	if err != nil { return nil, err }
-	var res BalanceInfo
-	json.Unmarshal(data, &res)
-	return &res, nil
+	return traced(ctx, c, "GetBalance", func(ctx context.Context) (*BalanceInfo, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetBalance")
+		defer cancel()
+		path := "/balance/" + address
+		data, ok := c.cacheGet("balance:" + address)
+		if !ok {
+			var err error
+			data, err = c.doRequest(ctx, "GetBalance", "GET", path, nil)
+			if err != nil { return nil, err }
+			if c.balanceTTL > 0 { c.cacheSet("balance:"+address, data, c.balanceTTL) }
+		}
+		var res BalanceInfo
+		if err := decodeResponse(path, c.adapt(data), &res); err != nil { return nil, err }
+		return &res, nil
+	})
 }
 
 func (c *OctraClient) GetNextNonce(ctx context.Context, address string) (uint64, error) {
-	info, err := c.GetBalance(ctx, address)
-	if err != nil { return 0, err }
-	return info.Nonce + 1, nil
+	return traced(ctx, c, "GetNextNonce", func(ctx context.Context) (uint64, error) {
+		info, err := c.GetBalance(ctx, address)
+		if err != nil { return 0, err }
+		return info.Nonce + 1, nil
+	})
 }
 
-func (c *OctraClient) SendTransaction(ctx context.Context, signedTx *SignedTransaction) (map[string]interface{}, error) {
-	data, err := c.doRequest(ctx, "POST", "/send-tx", signedTx.ToMap())
-	if err != nil { return nil, err }
-	var res map[string]interface{}
-	json.Unmarshal(data, &res)
-	return res, nil
+func (c *OctraClient) SendTransaction(ctx context.Context, signedTx *SignedTransaction) (*SubmitResult, error) {
+	return traced(ctx, c, "SendTransaction", func(ctx context.Context) (*SubmitResult, error) {
+		ctx, cancel := c.withDeadline(ctx, "SendTransaction")
+		defer cancel()
+		res, err := c.sendWithRetry(withOperation(ctx, "SendTransaction"), signedTx)
+		if err == nil { c.cacheDelete("balance:" + signedTx.Tx.From) }
+		return res, err
+	})
 }
 
-func (c *OctraClient) GetTransaction(ctx context.Context, hash string) (map[string]interface{}, error) {
-	data, err := c.doRequest(ctx, "GET", "/tx/"+hash, nil)
+func (c *OctraClient) submit(ctx context.Context, signedTx *SignedTransaction) (*SubmitResult, error) {
+	data, err := c.doRequest(ctx, "SendTransaction", "POST", "/send-tx", signedTx.ToMap())
 	if err != nil { return nil, err }
-	var res map[string]interface{}
-	json.Unmarshal(data, &res)
-	return res, nil
+	var res SubmitResult
+	if err := decodeResponse("/send-tx", data, &res); err != nil { return nil, err }
+	if res.Status != "" && res.Status != "accepted" {
+		rpcErr := newRPCError(http.StatusOK, "POST", "/send-tx", data)
+		rpcErr.Message = firstNonEmpty(res.Error, res.Message, res.Status)
+		return nil, rpcErr
+	}
+	return &res, nil
 }
 
-func (c *OctraClient) WaitTransaction(ctx context.Context, hash string, timeout time.Duration) (map[string]interface{}, error) {
-	deadline := time.Now().Add(timeout)
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done(): return nil, ctx.Err()
-		case <-ticker.C:
-			if time.Now().After(deadline) { return nil, fmt.Errorf("timeout") }
-			tx, err := c.GetTransaction(ctx, hash)
-			if err == nil && tx != nil {
-				if status, _ := tx["status"].(string); status == "confirmed" { return tx, nil }
-				if epoch, ok := tx["epoch"]; ok && epoch != nil { return tx, nil }
-			}
+func (c *OctraClient) GetTransaction(ctx context.Context, hash string) (*TransactionDetail, error) {
+	return traced(ctx, c, "GetTransaction", func(ctx context.Context) (*TransactionDetail, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetTransaction")
+		defer cancel()
+		path := "/tx/" + hash
+		data, cached := c.cacheGet("tx:" + hash)
+		if !cached {
+			var err error
+			data, err = c.doRequest(ctx, "GetTransaction", "GET", path, nil)
+			if err != nil { return nil, err }
 		}
-	}
+		var res TransactionDetail
+		if err := decodeResponse(path, c.adapt(data), &res); err != nil { return nil, err }
+		if res.Hash == "" { res.Hash = hash }
+		// Included transactions are immutable and can be cached forever; a
+		// "confirmed" answer without its epoch is fetched again.
+		if !cached && res.Epoch > 0 { c.cacheSet("tx:"+hash, data, 0) }
+		return &res, nil
+	})
 }
 
+// WaitTransaction waits up to timeout for hash to be confirmed. As before,
+// a non-positive timeout does not wait on ctx alone: it gives up after one
+// poll interval. See WaitForTransaction for status callbacks, polling
+// control and waits bounded only by ctx.
+func (c *OctraClient) WaitTransaction(ctx context.Context, hash string, timeout time.Duration) (*TransactionDetail, error) {
+	return traced(ctx, c, "WaitTransaction", func(ctx context.Context) (*TransactionDetail, error) {
+		if timeout <= 0 { timeout = WaitOptions{}.withDefaults(c).Interval }
+		receipt, err := c.WaitForTransaction(ctx, hash, WaitOptions{Timeout: timeout})
+		if err != nil { return nil, err }
+		return receipt.Detail, nil
+	})
+}
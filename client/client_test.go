@@ -1,10 +1,17 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 func TestOctraSDK_FullFlow(t *testing.T) {
@@ -85,3 +92,125 @@ func TestAtomsConversion(t *testing.T) {
 	}
 	fmt.Printf("\033[1;34m[DEBUG]\033[0m Conversion: %.2f OCT -> %s Atoms -> %s OCT\n", amount, atoms.String(), backToOCT)
 }
+
+func TestTransactionDetailDecoding(t *testing.T) {
+	body := `{
+		"tx_hash": "abc123",
+		"status": "confirmed",
+		"epoch": "42",
+		"parsed_tx": {
+			"from": "octSender",
+			"to_": "octReceiver",
+			"amount": "1.25 OCT",
+			"nonce": 7,
+			"ou": 1,
+			"timestamp": 1737273600.5,
+			"message": "hello"
+		},
+		"data": "{\"signature\":\"c2ln\",\"public_key\":\"cHVi\"}"
+	}`
+
+	var tx TransactionDetail
+	if err := json.Unmarshal([]byte(body), &tx); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if tx.Epoch != 42 || !tx.Confirmed() {
+		t.Errorf("Epoch/status mismatch: %+v", tx)
+	}
+	if tx.Parsed.To != "octReceiver" || tx.Parsed.Amount != "1.25" || tx.Parsed.OU != "1" {
+		t.Errorf("Parsed tx mismatch: %+v", tx.Parsed)
+	}
+	if tx.Signature != "c2ln" || tx.PublicKey != "cHVi" || tx.Message != "hello" {
+		t.Errorf("Envelope fields mismatch: %+v", tx)
+	}
+
+	atoms, err := tx.Parsed.AmountAtoms()
+	if err != nil || atoms.String() != "1250000" {
+		t.Errorf("AmountAtoms failed: got %v (%v)", atoms, err)
+	}
+	fmt.Printf("\033[1;34m[DEBUG]\033[0m Decoded Tx: %+v\n", tx)
+}
+
+func TestNodeInfoProbing(t *testing.T) {
+	legacy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/staging":
+			w.Write([]byte(`{"staged_transactions":[]}`))
+		case "/epoch/1":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"epoch not found"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer legacy.Close()
+
+	info, err := NewClient(legacy.URL).NodeInfo(context.Background())
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !info.Supports(FeatureStaging) || !info.Supports(FeatureEpochs) || info.Supports(FeatureEncryptedBalance) || info.Supports(FeatureStatus) {
+		t.Errorf("unexpected legacy features: %+v", info.Features)
+	}
+
+	modern := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			w.Write([]byte(`{"version":"2.1.0","epoch":88,"features":["status","epochs"],"fields":{"recipient":"to"}}`))
+		default:
+			w.Write([]byte(`{"status":"confirmed","epoch":3,"parsed_tx":{"from":"octA","recipient":"octB","amount":"1"}}`))
+		}
+	}))
+	defer modern.Close()
+
+	oc := NewClient(modern.URL)
+	if err := oc.requireFeature(context.Background(), FeatureStaging); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported for staging, got %v", err)
+	}
+	tx, err := oc.GetTransaction(context.Background(), "h1")
+	if err != nil || tx.Parsed.To != "octB" {
+		t.Errorf("decoder did not adapt node field names: %+v (%v)", tx, err)
+	}
+	fmt.Printf("\033[1;34m[DEBUG]\033[0m Node Info: %+v\n", oc.probe.info.Load())
+}
+
+func TestNodeInfoProbeDoesNotBlockRequests(t *testing.T) {
+	var statusHits int32
+	probing := make(chan struct{}, 8)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status" {
+			atomic.AddInt32(&statusHits, 1)
+			probing <- struct{}{}
+			time.Sleep(300 * time.Millisecond)
+			w.Write([]byte(`{"version":"2.1.0","features":["status"]}`))
+			return
+		}
+		w.Write([]byte(`{"balance":"1.5","nonce":2}`))
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := oc.NodeInfo(context.Background()); err != nil {
+				t.Errorf("NodeInfo failed: %v", err)
+			}
+		}()
+	}
+	<-probing
+
+	start := time.Now()
+	if _, err := oc.GetBalance(context.Background(), "octA"); err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("GetBalance waited %v behind the probe", elapsed)
+	}
+	wg.Wait()
+	if statusHits != 1 {
+		t.Errorf("expected concurrent callers to share one probe, got %d", statusHits)
+	}
+}
@@ -0,0 +1,41 @@
+// client/depth.go
+package client
+
+import "context"
+
+// Confirmations returns how many epochs a transaction included in epoch
+// included has when the node is at epoch tip, counting the including
+// epoch. It is 0 for transactions that are not included yet.
+func Confirmations(included, tip uint64) uint64 {
+	if included == 0 || tip < included {
+		return 0
+	}
+	return tip - included + 1
+}
+
+// CurrentEpoch returns the node's current epoch.
+func (c *OctraClient) CurrentEpoch(ctx context.Context) (uint64, error) {
+	return traced(ctx, c, "CurrentEpoch", func(ctx context.Context) (uint64, error) {
+		status, err := c.GetStatus(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return status.Epoch, nil
+	})
+}
+
+// TransactionConfirmations returns the number of confirmations of hash,
+// 0 while it is not included in an epoch.
+func (c *OctraClient) TransactionConfirmations(ctx context.Context, hash string) (uint64, error) {
+	return traced(ctx, c, "TransactionConfirmations", func(ctx context.Context) (uint64, error) {
+		tx, err := c.GetTransaction(ctx, hash)
+		if err != nil || !tx.Confirmed() {
+			return 0, err
+		}
+		tip, err := c.CurrentEpoch(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return Confirmations(tx.Epoch, max(tip, tx.Epoch)), nil
+	})
+}
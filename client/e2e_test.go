@@ -0,0 +1,538 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+	"github.com/dayuwidayadi57/octra/octratest"
+)
+
+func signTransfer(t *testing.T, from, priv, to string, atoms int64, nonce uint64, msg string) *client.SignedTransaction {
+	t.Helper()
+	return signTransferAt(t, from, priv, to, atoms, nonce, msg, 1737273600)
+}
+
+func signTransferAt(t *testing.T, from, priv, to string, atoms int64, nonce uint64, msg string, unix int64) *client.SignedTransaction {
+	t.Helper()
+	tx := client.Transaction{
+		From:      from,
+		To:        to,
+		Amount:    strconv.FormatInt(atoms, 10),
+		Nonce:     nonce,
+		Timestamp: json.Number(strconv.FormatInt(unix, 10)),
+		Message:   msg,
+	}
+	signed, err := client.SignTransaction(tx, priv)
+	if err != nil {
+		t.Fatalf("Signing failed: %v", err)
+	}
+	return signed
+}
+
+func TestEndToEndAgainstFakeNode(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+	ctx := context.Background()
+
+	sender, _, priv, _ := client.GenerateNewKeyPair()
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	node.Fund(sender, 10_000_000)
+
+	signed := signTransfer(t, sender, priv, receiver, 2_500_000, 1, "invoice #1")
+	res, err := oc.SendTransaction(ctx, signed)
+	if err != nil || res.TxHash != signed.Hash() {
+		t.Fatalf("Send failed: %+v (%v)", res, err)
+	}
+
+	if _, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 1_000_000, 1, "")); !errors.Is(err, client.ErrNonceTooLow) {
+		t.Errorf("expected ErrNonceTooLow for reused nonce, got %v", err)
+	}
+	if _, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 9_000_000, 2, "")); !errors.Is(err, client.ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance, got %v", err)
+	}
+
+	time.AfterFunc(100*time.Millisecond, func() { node.ProduceEpoch() })
+	confirmed, err := oc.WaitTransaction(ctx, res.TxHash, 10*time.Second)
+	if err != nil || confirmed.Epoch != 1 || confirmed.Message != "invoice #1" {
+		t.Fatalf("Wait failed: %+v (%v)", confirmed, err)
+	}
+
+	history, err := oc.GetHistory(ctx, receiver, 10)
+	if err != nil || len(history) != 1 || history[0].From != sender || history[0].Amount != "2.500000" {
+		t.Fatalf("History mismatch: %+v (%v)", history, err)
+	}
+
+	stats, err := oc.GetStats(ctx, sender)
+	if err != nil || stats.TotalOut.Int64() != 2_500_000 || stats.TxCount != 1 {
+		t.Fatalf("Stats mismatch: %+v (%v)", stats, err)
+	}
+
+	bal, err := oc.GetBalance(ctx, sender)
+	if err != nil || bal.BalanceRaw != "7500000" || bal.Nonce != 1 {
+		t.Errorf("Balance mismatch: %+v (%v)", bal, err)
+	}
+}
+
+func TestStatusEpochAndStaging(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+	ctx := context.Background()
+
+	sender, _, priv, _ := client.GenerateNewKeyPair()
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	node.Fund(sender, 10_000_000)
+
+	for nonce := uint64(1); nonce <= 2; nonce++ {
+		if _, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 1_000_000, nonce, "")); err != nil {
+			t.Fatalf("Send %d failed: %v", nonce, err)
+		}
+	}
+
+	status, err := oc.GetStatus(ctx)
+	if err != nil || status.Version != "octratest" || status.Epoch != 0 || status.StagedCount != 2 {
+		t.Fatalf("Status mismatch: %+v (%v)", status, err)
+	}
+
+	staged, err := oc.GetStagedTransactions(ctx, sender)
+	if err != nil || len(staged) != 2 || staged[1].Nonce != 2 || staged[0].Amount != "1.000000" {
+		t.Fatalf("Staging mismatch: %+v (%v)", staged, err)
+	}
+	if others, err := oc.GetStagedTransactions(ctx, receiver); err != nil || len(others) != 0 {
+		t.Errorf("expected no staged txs for receiver, got %+v (%v)", others, err)
+	}
+
+	if nonce, err := oc.GetPendingNonce(ctx, sender); err != nil || nonce != 3 {
+		t.Errorf("expected pending nonce 3, got %d (%v)", nonce, err)
+	}
+
+	node.ProduceEpoch()
+	epoch, err := oc.GetEpoch(ctx, 1)
+	if err != nil || epoch.Number != 1 || len(epoch.Transactions) != 2 {
+		t.Fatalf("Epoch mismatch: %+v (%v)", epoch, err)
+	}
+	if tx := epoch.Transactions[0]; !tx.Confirmed() || tx.Parsed.From != sender || tx.Epoch != 1 {
+		t.Errorf("Epoch tx mismatch: %+v", tx)
+	}
+	if _, err := oc.GetEpoch(ctx, 9); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for future epoch, got %v", err)
+	}
+	if nonce, err := oc.GetPendingNonce(ctx, sender); err != nil || nonce != 3 {
+		t.Errorf("expected nonce 3 after epoch, got %d (%v)", nonce, err)
+	}
+}
+
+func TestGetBalancesBatchEndpoint(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+
+	addrs := make([]string, 250)
+	for i := range addrs {
+		addrs[i], _, _, _ = client.GenerateNewKeyPair()
+		node.Fund(addrs[i], int64(i+1))
+	}
+	results := oc.GetBalances(context.Background(), addrs)
+	for i, r := range results {
+		if r.Err != nil || r.Address != addrs[i] || r.Info.BalanceRaw != strconv.Itoa(i+1) {
+			t.Fatalf("result %d mismatch: %+v", i, r)
+		}
+	}
+}
+
+type pathCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (p *pathCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	p.mu.Lock()
+	p.counts[strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2)[0]]++
+	p.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWaitTransactionsSharedLoop(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	counter := &pathCounter{counts: make(map[string]int)}
+	oc := node.Client(client.WithTransport(counter), client.WithConcurrency(4))
+	ctx := context.Background()
+
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	var hashes []string
+	for i := 0; i < 5; i++ {
+		sender, _, priv, _ := client.GenerateNewKeyPair()
+		node.Fund(sender, 10_000_000)
+		for nonce := uint64(1); nonce <= 4; nonce++ {
+			res, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 1_000, nonce, ""))
+			if err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			hashes = append(hashes, res.TxHash)
+		}
+		if i == 1 {
+			node.ProduceEpoch() // confirmed before the wait starts
+		}
+	}
+	unknown := strings.Repeat("0", 64)
+
+	var statusMu sync.Mutex
+	transitions := 0
+	opts := client.WaitOptions{
+		Interval: 20 * time.Millisecond,
+		Timeout:  time.Second,
+		OnStatus: func(string, client.TxStatus, *client.TransactionDetail) {
+			statusMu.Lock()
+			transitions++
+			statusMu.Unlock()
+		},
+	}
+	results := oc.WaitTransactions(ctx, append(hashes, unknown), opts)
+	time.AfterFunc(100*time.Millisecond, func() { node.ProduceEpoch() })
+
+	confirmed := 0
+	for res := range results {
+		switch {
+		case res.Hash == unknown:
+			if !errors.Is(res.Err, client.ErrWaitTimeout) {
+				t.Errorf("expected timeout for unknown hash, got %v", res.Err)
+			}
+		case res.Err != nil || res.Receipt.Epoch == 0:
+			t.Errorf("hash %s failed: %+v", res.Hash, res)
+		default:
+			confirmed++
+		}
+	}
+	if confirmed != len(hashes) {
+		t.Errorf("expected %d confirmations, got %d", len(hashes), confirmed)
+	}
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+	t.Logf("requests by route: %v", counter.counts)
+	// One lookup per hash in the first round; afterwards only the unknown
+	// hash is looked up individually, plus one round of lookups if the epoch
+	// lands between the status and staging reads.
+	if lookups := counter.counts["tx"]; lookups > 2*len(hashes)+1+counter.counts["staging"] {
+		t.Errorf("expected the shared loop to avoid per-hash polling, saw %d lookups", lookups)
+	}
+	if counter.counts["epoch"] == 0 || counter.counts["staging"] == 0 {
+		t.Errorf("expected epoch and staging scans, got %v", counter.counts)
+	}
+}
+
+func TestConfirmationDepth(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client(client.WithConfirmationDepth(2))
+	ctx := context.Background()
+
+	sender, _, priv, _ := client.GenerateNewKeyPair()
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	node.Fund(sender, 10_000_000)
+	first, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 1_000, 1, ""))
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	second, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 1_000, 2, ""))
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	node.ProduceEpoch()
+
+	if history, err := oc.GetHistory(ctx, receiver, 10); err != nil || len(history) != 0 {
+		t.Fatalf("expected no final history at depth 1, got %+v (%v)", history, err)
+	}
+	if n, err := oc.TransactionConfirmations(ctx, first.TxHash); err != nil || n != 1 {
+		t.Errorf("expected 1 confirmation, got %d (%v)", n, err)
+	}
+
+	var progress []uint64
+	node.StartEpochs(30 * time.Millisecond)
+	receipt, err := oc.WaitForTransaction(ctx, first.TxHash, client.WaitOptions{
+		Depth:           3,
+		Interval:        10 * time.Millisecond,
+		Timeout:         5 * time.Second,
+		OnConfirmations: func(_ string, n uint64) { progress = append(progress, n) },
+	})
+	if err != nil || receipt.Epoch != 1 || receipt.Confirmations < 3 {
+		t.Fatalf("Wait failed: %+v (%v)", receipt, err)
+	}
+	if len(progress) < 2 || progress[len(progress)-1] != receipt.Confirmations {
+		t.Errorf("unexpected confirmation progress %v", progress)
+	}
+
+	for res := range oc.WaitTransactions(ctx, []string{second.TxHash}, client.WaitOptions{Depth: 4, Interval: 10 * time.Millisecond, Timeout: 5 * time.Second}) {
+		if res.Err != nil || res.Receipt.Confirmations < 4 {
+			t.Errorf("WaitTransactions failed: %+v", res)
+		}
+	}
+
+	history, err := oc.GetHistory(ctx, receiver, 10)
+	if err != nil || len(history) != 2 || history[0].Confirmations < 4 {
+		t.Fatalf("History mismatch: %+v (%v)", history, err)
+	}
+}
+
+func collectEvents(t *testing.T, events <-chan client.TransferEvent, n int) []client.TransferEvent {
+	t.Helper()
+	var got []client.TransferEvent
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case ev := <-events:
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("expected %d events, got %d: %+v", n, len(got), got)
+		}
+	}
+	return got
+}
+
+func TestWatcherResumesFromCursor(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+	ctx := context.Background()
+
+	alice, _, alicePriv, _ := client.GenerateNewKeyPair()
+	bob, _, bobPriv, _ := client.GenerateNewKeyPair()
+	node.Fund(alice, 10_000_000)
+	node.Fund(bob, 10_000_000)
+	if _, err := oc.SendTransaction(ctx, signTransfer(t, alice, alicePriv, bob, 1_000, 1, "before")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	node.ProduceEpoch()
+
+	store, err := client.NewFileCursorStore(t.TempDir() + "/cursors.json")
+	if err != nil {
+		t.Fatalf("Cursor store failed: %v", err)
+	}
+	opts := client.WatchOptions{Interval: 20 * time.Millisecond, Store: store, PageSize: 3}
+
+	watchCtx, stop := context.WithCancel(ctx)
+	w := oc.NewWatcher(opts)
+	w.Add(alice)
+	w.Add(bob)
+	done := make(chan error, 1)
+	go func() { done <- w.Run(watchCtx) }()
+	time.Sleep(50 * time.Millisecond) // let the watcher record its starting cursors
+
+	if _, err := oc.SendTransaction(ctx, signTransfer(t, alice, alicePriv, bob, 2_500_000, 2, "invoice #7")); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	node.ProduceEpoch()
+
+	got := collectEvents(t, w.Events(), 2)
+	for _, ev := range got {
+		want := client.TransferEvent{Address: alice, Direction: client.DirectionOut, Counterparty: bob}
+		if ev.Address == bob {
+			want = client.TransferEvent{Address: bob, Direction: client.DirectionIn, Counterparty: alice}
+		}
+		if ev.Direction != want.Direction || ev.Counterparty != want.Counterparty || ev.Amount.Int64() != 2_500_000 ||
+			ev.Message != "invoice #7" || ev.Epoch != 2 {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	}
+	stop()
+	<-done
+
+	// Transfers made while the watcher is down are delivered after a
+	// restart, even when they exceed one page, and nothing is replayed.
+	for nonce := uint64(1); nonce <= 7; nonce++ {
+		if _, err := oc.SendTransaction(ctx, signTransfer(t, bob, bobPriv, alice, 1_000, nonce, "")); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	node.ProduceEpoch()
+
+	reopened, err := client.NewFileCursorStore(store.Path)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	opts.Store = reopened
+	watchCtx, stop = context.WithCancel(ctx)
+	defer stop()
+	events := oc.WatchAddress(watchCtx, alice, opts)
+	got = collectEvents(t, events, 7)
+	for i, ev := range got {
+		if ev.Direction != client.DirectionIn || ev.Counterparty != bob || ev.Epoch != 3 {
+			t.Errorf("event %d mismatch: %+v", i, ev)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Errorf("unexpected extra event: %+v", ev)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSubscribeEpochs(t *testing.T) {
+	for _, push := range []bool{true, false} {
+		t.Run(map[bool]string{true: "stream", false: "polling"}[push], func(t *testing.T) {
+			node := octratest.NewNode()
+			defer node.Close()
+			if !push {
+				node.Disable("epoch_stream")
+			}
+			oc := node.Client(client.WithPollInterval(20 * time.Millisecond))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			sender, _, priv, _ := client.GenerateNewKeyPair()
+			receiver, _, _, _ := client.GenerateNewKeyPair()
+			node.Fund(sender, 10_000_000)
+			send := func(nonce uint64) {
+				if _, err := oc.SendTransaction(ctx, signTransfer(t, sender, priv, receiver, 1_000, nonce, "")); err != nil {
+					t.Fatalf("Send failed: %v", err)
+				}
+			}
+			send(1)
+			node.ProduceEpoch()
+			node.ProduceEpoch()
+
+			sub := oc.SubscribeEpochs(ctx, 1)
+			next := func() *client.Epoch {
+				t.Helper()
+				select {
+				case epoch, ok := <-sub.Epochs():
+					if !ok {
+						t.Fatalf("subscription ended: %v", sub.Err())
+					}
+					return epoch
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for an epoch")
+				}
+				return nil
+			}
+
+			if e := next(); e.Number != 1 || len(e.Transactions) != 1 {
+				t.Fatalf("expected back-filled epoch 1 with one tx, got %+v", e)
+			}
+			if e := next(); e.Number != 2 || len(e.Transactions) != 0 {
+				t.Fatalf("expected empty epoch 2, got %+v", e)
+			}
+			send(2)
+			node.ProduceEpoch()
+			if e := next(); e.Number != 3 || len(e.Transactions) != 1 || e.Transactions[0].Parsed.Nonce != 2 {
+				t.Fatalf("expected epoch 3, got %+v", e)
+			}
+
+			// Epochs produced while disconnected are back-filled in order.
+			node.Server.CloseClientConnections()
+			node.ProduceEpoch()
+			node.ProduceEpoch()
+			for want := uint64(4); want <= 5; want++ {
+				if e := next(); e.Number != want {
+					t.Fatalf("expected epoch %d after reconnect, got %d", want, e.Number)
+				}
+			}
+
+			cancel()
+			for range sub.Epochs() {
+			}
+			if !errors.Is(sub.Err(), context.Canceled) {
+				t.Errorf("expected context.Canceled, got %v", sub.Err())
+			}
+		})
+	}
+}
+
+func TestHistoryIterator(t *testing.T) {
+	for _, paging := range []bool{true, false} {
+		t.Run(map[bool]string{true: "offset", false: "limit"}[paging], func(t *testing.T) {
+			node := octratest.NewNode()
+			defer node.Close()
+			if !paging {
+				node.Disable("history_paging")
+			}
+			counter := &pathCounter{counts: make(map[string]int)}
+			oc := node.Client(client.WithTransport(counter))
+			ctx := context.Background()
+
+			alice, _, alicePriv, _ := client.GenerateNewKeyPair()
+			bob, _, bobPriv, _ := client.GenerateNewKeyPair()
+			node.Fund(alice, 10_000_000)
+			node.Fund(bob, 10_000_000)
+			const base = 1737273600
+			for nonce := uint64(1); nonce <= 55; nonce++ {
+				if _, err := oc.SendTransaction(ctx, signTransferAt(t, alice, alicePriv, bob, 1_000, nonce, "", base+int64(nonce))); err != nil {
+					t.Fatalf("Send failed: %v", err)
+				}
+				if nonce%11 == 0 {
+					node.ProduceEpoch() // epochs 1..5
+				}
+			}
+			for nonce := uint64(1); nonce <= 3; nonce++ {
+				if _, err := oc.SendTransaction(ctx, signTransferAt(t, bob, bobPriv, alice, 7_000, nonce, "", base+100)); err != nil {
+					t.Fatalf("Send failed: %v", err)
+				}
+			}
+			node.ProduceEpoch() // epoch 6
+
+			collect := func(filter client.HistoryFilter) []client.TransactionHistory {
+				t.Helper()
+				var out []client.TransactionHistory
+				for h, err := range oc.History(ctx, alice, filter) {
+					if err != nil {
+						t.Fatalf("History failed: %v", err)
+					}
+					out = append(out, h)
+				}
+				return out
+			}
+
+			all := collect(client.HistoryFilter{PageSize: 7})
+			if len(all) != 58 || all[0].Epoch != 6 || all[57].Epoch != 1 {
+				t.Fatalf("expected 58 entries newest first, got %d", len(all))
+			}
+			seen := make(map[string]bool)
+			for _, h := range all {
+				if seen[h.Hash] {
+					t.Fatalf("duplicate entry %s", h.Hash)
+				}
+				seen[h.Hash] = true
+			}
+
+			if got := collect(client.HistoryFilter{FromEpoch: 2, ToEpoch: 3, PageSize: 7}); len(got) != 22 {
+				t.Errorf("expected 22 entries in epochs 2-3, got %d", len(got))
+			}
+			if got := collect(client.HistoryFilter{Direction: client.DirectionIn}); len(got) != 3 || got[0].From != bob {
+				t.Errorf("expected 3 incoming entries, got %+v", got)
+			}
+			window := client.HistoryFilter{Since: time.Unix(base+10, 0), Until: time.Unix(base+19, 0)}
+			if got := collect(window); len(got) != 10 {
+				t.Errorf("expected 10 entries in the time window, got %d", len(got))
+			}
+
+			counter.mu.Lock()
+			counter.counts["address"] = 0
+			counter.mu.Unlock()
+			n := 0
+			for range oc.History(ctx, alice, client.HistoryFilter{PageSize: 5}) {
+				if n++; n == 6 {
+					break
+				}
+			}
+			counter.mu.Lock()
+			if pages := counter.counts["address"]; pages != 2 {
+				t.Errorf("expected breaking out to stop after 2 pages, fetched %d", pages)
+			}
+			counter.mu.Unlock()
+
+			stats, err := oc.GetStats(ctx, alice)
+			if err != nil || stats.TxCount != 58 || stats.TotalOut.Int64() != 55_000 || stats.TotalIn.Int64() != 21_000 {
+				t.Errorf("Stats mismatch: %+v (%v)", stats, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// client/endpoints.go
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoEndpoints is returned for requests through an endpoint pool without
+// any endpoint.
+var ErrNoEndpoints = errors.New("octra: no endpoints configured")
+
+// Breaker states of an endpoint.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// EndpointPool spreads OctraClient requests over several RPC nodes. Each node
+// is scored by observed latency, error rate and how far its reported epoch
+// lags behind the best node; a node that keeps failing is taken out of
+// rotation by a circuit breaker until OpenTimeout has passed.
+type EndpointPool struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	MaxEpochLag      uint64
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+}
+
+// EndpointStats is a snapshot of an endpoint's health.
+type EndpointStats struct {
+	URL                 string
+	Latency             time.Duration
+	ErrorRate           float64
+	Epoch               uint64
+	State               string
+	ConsecutiveFailures int
+	Requests            uint64
+	Failures            uint64
+}
+
+type endpoint struct {
+	url         string
+	latency     time.Duration
+	errorRate   float64
+	epoch       uint64
+	state       string
+	openedAt    time.Time
+	failures    int
+	trial       bool
+	requests    uint64
+	totalFailed uint64
+}
+
+const healthSmoothing = 0.2
+
+func NewEndpointPool(urls ...string) *EndpointPool {
+	p := &EndpointPool{
+		FailureThreshold: 3,
+		OpenTimeout:      30 * time.Second,
+		MaxEpochLag:      2,
+	}
+	for _, u := range urls {
+		p.endpoints = append(p.endpoints, &endpoint{url: strings.TrimSuffix(u, "/"), state: BreakerClosed})
+	}
+	return p
+}
+
+// NewMultiClient returns an OctraClient that routes every call to the
+// healthiest of the given RPC endpoints and fails over between them. With
+// no urls every request fails with ErrNoEndpoints.
+func NewMultiClient(urls []string, opts ...Option) *OctraClient {
+	var baseURL string
+	if len(urls) > 0 {
+		baseURL = urls[0]
+	}
+	return NewClient(baseURL, append([]Option{WithEndpointPool(NewEndpointPool(urls...))}, opts...)...)
+}
+
+// pick returns the endpoint the next request should use, or nil if the
+// pool is empty.
+func (p *EndpointPool) pick() *endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.endpoints) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	best := p.maxEpoch()
+	var chosen *endpoint
+	var chosenScore float64
+	for _, e := range p.endpoints {
+		if e.state == BreakerOpen && now.Sub(e.openedAt) >= p.OpenTimeout {
+			e.state = BreakerHalfOpen
+			e.trial = false
+		}
+		if e.state == BreakerOpen || (e.state == BreakerHalfOpen && e.trial) {
+			continue
+		}
+		score := p.score(e, best)
+		if chosen == nil || score < chosenScore {
+			chosen, chosenScore = e, score
+		}
+	}
+
+	if chosen == nil {
+		// Every breaker is open: fall back to the node that failed longest ago.
+		for _, e := range p.endpoints {
+			if chosen == nil || e.openedAt.Before(chosen.openedAt) {
+				chosen = e
+			}
+		}
+	}
+	if chosen.state == BreakerHalfOpen {
+		chosen.trial = true
+	}
+	chosen.requests++
+	return chosen
+}
+
+func (p *EndpointPool) score(e *endpoint, bestEpoch uint64) float64 {
+	latency := float64(e.latency) / float64(time.Millisecond)
+	if latency == 0 {
+		latency = 1
+	}
+	score := latency * (1 + 10*e.errorRate)
+	if lag := bestEpoch - e.epoch; e.epoch > 0 && lag > p.MaxEpochLag {
+		score *= float64(lag)
+	}
+	return score
+}
+
+func (p *EndpointPool) maxEpoch() uint64 {
+	var best uint64
+	for _, e := range p.endpoints {
+		if e.epoch > best {
+			best = e.epoch
+		}
+	}
+	return best
+}
+
+// report records the outcome of a request sent to e. Only transport errors,
+// 429 and 5xx answers count against a node; other RPC errors are regular
+// answers from a healthy node. A request the caller cancelled says nothing
+// about the node and only ends a half-open trial.
+func (p *EndpointPool) report(e *endpoint, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e.trial = false
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	failed := nodeFailure(err)
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration((1-healthSmoothing)*float64(e.latency) + healthSmoothing*float64(latency))
+	}
+	sample := 0.0
+	if failed {
+		sample = 1
+	}
+	e.errorRate = (1-healthSmoothing)*e.errorRate + healthSmoothing*sample
+
+	if !failed {
+		e.failures = 0
+		e.state = BreakerClosed
+		return
+	}
+	e.failures++
+	e.totalFailed++
+	if e.state == BreakerHalfOpen || e.failures >= p.FailureThreshold {
+		e.state = BreakerOpen
+		e.openedAt = time.Now()
+	}
+}
+
+func nodeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Temporary()
+	}
+	return true
+}
+
+// reportStatus records the epoch of a /status answer from url.
+func (p *EndpointPool) reportStatus(url string, data []byte) {
+	var status struct {
+		Epoch        flexUint `json:"epoch"`
+		CurrentEpoch flexUint `json:"current_epoch"`
+	}
+	if decodeResponse("/status", data, &status) == nil {
+		p.ReportEpoch(url, uint64(max(status.Epoch, status.CurrentEpoch)))
+	}
+}
+
+// ReportEpoch records the latest epoch a node claims to have produced. The
+// client calls it for every /status answer it routes through the pool, so
+// nodes serving traffic stay current; MonitorEndpoints also refreshes idle
+// nodes.
+func (p *EndpointPool) ReportEpoch(url string, epoch uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, e := range p.endpoints {
+		if e.url == strings.TrimSuffix(url, "/") && epoch > e.epoch {
+			e.epoch = epoch
+		}
+	}
+}
+
+func (p *EndpointPool) Stats() []EndpointStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]EndpointStats, 0, len(p.endpoints))
+	for _, e := range p.endpoints {
+		stats = append(stats, EndpointStats{
+			URL:                 e.url,
+			Latency:             e.latency,
+			ErrorRate:           e.errorRate,
+			Epoch:               e.epoch,
+			State:               e.state,
+			ConsecutiveFailures: e.failures,
+			Requests:            e.requests,
+			Failures:            e.totalFailed,
+		})
+	}
+	return stats
+}
+
+// ProbeEndpoints queries /status on every endpoint to refresh latency and
+// epoch height. It is a no-op for single-endpoint clients.
+func (c *OctraClient) ProbeEndpoints(ctx context.Context) {
+	if c.endpoints == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			data, err := c.doAt(ctx, e.url, http.MethodGet, "/status", nil, nil)
+			c.endpoints.report(e, time.Since(start), err)
+			if err == nil {
+				c.endpoints.reportStatus(e.url, data)
+			}
+		}(e)
+	}
+	wg.Wait()
+}
+
+// MonitorEndpoints probes all endpoints every interval until ctx is done.
+func (c *OctraClient) MonitorEndpoints(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.ProbeEndpoints(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
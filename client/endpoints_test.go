@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMultiClientFailover(t *testing.T) {
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer bad.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status" {
+			w.Write([]byte(`{"epoch":100}`))
+			return
+		}
+		w.Write([]byte(`{"address":"oct1","balance":"2","nonce":9}`))
+	}))
+	defer good.Close()
+
+	pool := NewEndpointPool(bad.URL, good.URL)
+	pool.FailureThreshold = 2
+	oc := NewClient(bad.URL, WithEndpointPool(pool), WithRetryPolicy(fastRetry()))
+
+	for i := 0; i < 5; i++ {
+		info, err := oc.GetBalance(context.Background(), "oct1")
+		if err != nil || info.Nonce != 9 {
+			t.Fatalf("call %d: expected failover to healthy node, got %+v (%v)", i, info, err)
+		}
+	}
+
+	oc.ProbeEndpoints(context.Background())
+	for _, st := range oc.Endpoints().Stats() {
+		switch st.URL {
+		case bad.URL:
+			if st.State != BreakerOpen {
+				t.Errorf("failing node should have an open breaker, got %+v", st)
+			}
+		case good.URL:
+			if st.State != BreakerClosed || st.Epoch != 100 {
+				t.Errorf("healthy node stats unexpected: %+v", st)
+			}
+		}
+	}
+}
+
+func TestEpochStreamReportsToPool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			w.Write([]byte(`{"version":"test","epoch":1,"features":["status","epochs","epoch_stream"]}`))
+		case "/epochs/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("data: {\"epoch\":1}\n\n"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			w.Write([]byte(`{"epoch":1,"transactions":[]}`))
+		}
+	}))
+	defer srv.Close()
+
+	pool := NewEndpointPool(srv.URL)
+	pool.endpoints[0].state = BreakerHalfOpen
+	var streamed []*Call
+	oc := NewClient(srv.URL, WithEndpointPool(pool), WithInterceptors(func(ctx context.Context, call *Call, next Invoker) error {
+		if call.Path == "/epochs/stream?from=1" {
+			streamed = append(streamed, call)
+		}
+		return next(ctx, call)
+	}))
+	// Skip probing so the stream is the request that takes the trial slot.
+	oc.probe.info.Store(&NodeInfo{Features: map[Feature]bool{FeatureStatus: true, FeatureEpochs: true, FeatureEpochStream: true}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := oc.SubscribeEpochs(ctx, 1)
+	if epoch := <-sub.Epochs(); epoch == nil || epoch.Number != 1 {
+		t.Fatalf("expected epoch 1, got %+v", epoch)
+	}
+	if st := pool.Stats()[0]; st.State != BreakerClosed {
+		t.Errorf("a working stream must close the breaker, got %+v", st)
+	}
+	if len(streamed) != 1 || streamed[0].Parent == nil || streamed[0].Parent.Operation != "SubscribeEpochs" {
+		t.Errorf("expected the stream to pass the interceptors under SubscribeEpochs, got %+v", streamed)
+	}
+}
+
+func TestMultiClientWithoutEndpoints(t *testing.T) {
+	oc := NewMultiClient(nil, WithRetryPolicy(fastRetry()))
+	if _, err := oc.GetBalance(context.Background(), "oct1"); !errors.Is(err, ErrNoEndpoints) {
+		t.Errorf("expected ErrNoEndpoints, got %v", err)
+	}
+}
+
+func TestCancelledRequestIsNeutral(t *testing.T) {
+	pool := NewEndpointPool("http://a")
+	pool.FailureThreshold = 3
+	e := pool.endpoints[0]
+	pool.report(e, time.Millisecond, errors.New("connection reset"))
+	pool.report(e, time.Millisecond, errors.New("connection reset"))
+	pool.report(e, time.Millisecond, context.Canceled)
+	if e.failures != 2 || e.state != BreakerClosed {
+		t.Errorf("cancellation must not reset failures: %+v", pool.Stats()[0])
+	}
+
+	e.state, e.trial = BreakerHalfOpen, true
+	pool.report(e, time.Millisecond, fmt.Errorf("get: %w", context.Canceled))
+	if e.state != BreakerHalfOpen || e.trial || e.failures != 2 {
+		t.Errorf("cancelled trial must only free the half-open slot: %+v", pool.Stats()[0])
+	}
+}
+
+func TestStatusAnswersUpdateEpochs(t *testing.T) {
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"current_epoch":42}`))
+	}))
+	defer node.Close()
+
+	oc := NewMultiClient([]string{node.URL}, WithRetryPolicy(fastRetry()))
+	if _, err := oc.CurrentEpoch(context.Background()); err != nil {
+		t.Fatalf("CurrentEpoch failed: %v", err)
+	}
+	if got := oc.Endpoints().Stats()[0].Epoch; got != 42 {
+		t.Errorf("expected the pool to record epoch 42, got %d", got)
+	}
+}
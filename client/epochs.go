@@ -0,0 +1,202 @@
+// client/epochs.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NodeStatus is the node's answer to GET /status.
+type NodeStatus struct {
+	Version     string `json:"version"`
+	Network     string `json:"network,omitempty"`
+	Epoch       uint64 `json:"epoch"`
+	StagedCount int    `json:"staged_count"`
+}
+
+func (s *NodeStatus) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Version      flexString `json:"version"`
+		Network      flexString `json:"network"`
+		Epoch        flexUint   `json:"epoch"`
+		CurrentEpoch flexUint   `json:"current_epoch"`
+		StagedCount  flexUint   `json:"staged_count"`
+		Staged       flexUint   `json:"staged"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*s = NodeStatus{
+		Version:     string(aux.Version),
+		Network:     string(aux.Network),
+		Epoch:       uint64(max(aux.Epoch, aux.CurrentEpoch)),
+		StagedCount: int(max(aux.StagedCount, aux.Staged)),
+	}
+	return nil
+}
+
+// Epoch is a produced epoch together with the transactions it includes.
+type Epoch struct {
+	Number       uint64              `json:"epoch"`
+	Timestamp    json.Number         `json:"timestamp,omitempty"`
+	Transactions []TransactionDetail `json:"transactions"`
+}
+
+func (e *Epoch) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Epoch        flexUint            `json:"epoch"`
+		Number       flexUint            `json:"number"`
+		Timestamp    flexString          `json:"timestamp"`
+		Transactions []TransactionDetail `json:"transactions"`
+		Txs          []TransactionDetail `json:"txs"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*e = Epoch{
+		Number:       uint64(max(aux.Epoch, aux.Number)),
+		Timestamp:    json.Number(aux.Timestamp),
+		Transactions: append(aux.Transactions, aux.Txs...),
+	}
+	for i := range e.Transactions {
+		tx := &e.Transactions[i]
+		if tx.Epoch == 0 {
+			tx.Epoch = e.Number
+		}
+		if tx.Status == "" {
+			tx.Status = "confirmed"
+		}
+	}
+	return nil
+}
+
+// StagedTransaction is a transaction accepted by the node but not yet
+// included in an epoch.
+type StagedTransaction struct {
+	Hash string `json:"hash"`
+	ParsedTransaction
+}
+
+func (s *StagedTransaction) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		TxHash flexString `json:"tx_hash"`
+		Hash   flexString `json:"hash"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	if err := s.ParsedTransaction.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	s.Hash = firstNonEmpty(string(aux.TxHash), string(aux.Hash))
+	return nil
+}
+
+// GetStatus returns the node version, current epoch and staging size.
+func (c *OctraClient) GetStatus(ctx context.Context) (*NodeStatus, error) {
+	return traced(ctx, c, "GetStatus", func(ctx context.Context) (*NodeStatus, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetStatus")
+		defer cancel()
+		if err := c.requireFeature(ctx, FeatureStatus); err != nil {
+			return nil, err
+		}
+		data, err := c.doRequest(ctx, "GetStatus", "GET", "/status", nil)
+		if err != nil {
+			return nil, err
+		}
+		var res NodeStatus
+		if err := decodeResponse("/status", c.adapt(data), &res); err != nil {
+			return nil, err
+		}
+		return &res, nil
+	})
+}
+
+// GetEpoch fetches epoch n with the transactions it includes.
+func (c *OctraClient) GetEpoch(ctx context.Context, n uint64) (*Epoch, error) {
+	return traced(ctx, c, "GetEpoch", func(ctx context.Context) (*Epoch, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetEpoch")
+		defer cancel()
+		if err := c.requireFeature(ctx, FeatureEpochs); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("/epoch/%d", n)
+		data, err := c.doRequest(ctx, "GetEpoch", "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res Epoch
+		if err := decodeResponse(path, c.adapt(data), &res); err != nil {
+			return nil, err
+		}
+		if res.Number == 0 {
+			res.Number = n
+		}
+		return &res, nil
+	})
+}
+
+// GetStagedTransactions lists transactions waiting for the next epoch. When
+// sender is not empty only its transactions are returned.
+func (c *OctraClient) GetStagedTransactions(ctx context.Context, sender string) ([]StagedTransaction, error) {
+	return traced(ctx, c, "GetStagedTransactions", func(ctx context.Context) ([]StagedTransaction, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetStagedTransactions")
+		defer cancel()
+		if err := c.requireFeature(ctx, FeatureStaging); err != nil {
+			return nil, err
+		}
+		path := "/staging"
+		if sender != "" {
+			path += "?from=" + url.QueryEscape(sender)
+		}
+		data, err := c.doRequest(ctx, "GetStagedTransactions", "GET", path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			Staged []StagedTransaction `json:"staged_transactions"`
+		}
+		if err := decodeResponse(path, c.adapt(data), &res); err != nil {
+			return nil, err
+		}
+		if sender == "" {
+			return res.Staged, nil
+		}
+		filtered := res.Staged[:0]
+		for _, tx := range res.Staged {
+			if strings.EqualFold(tx.From, sender) {
+				filtered = append(filtered, tx)
+			}
+		}
+		return filtered, nil
+	})
+}
+
+// GetPendingNonce returns the next nonce for address, accounting for its
+// transactions still in staging. It falls back to GetNextNonce on nodes
+// without a staging endpoint.
+func (c *OctraClient) GetPendingNonce(ctx context.Context, address string) (uint64, error) {
+	return traced(ctx, c, "GetPendingNonce", func(ctx context.Context) (uint64, error) {
+		next, err := c.GetNextNonce(ctx, address)
+		if err != nil {
+			return 0, err
+		}
+		staged, err := c.GetStagedTransactions(ctx, address)
+		if err != nil {
+			if errors.Is(err, ErrUnsupported) {
+				return next, nil
+			}
+			return 0, err
+		}
+		for _, tx := range staged {
+			if tx.Nonce >= next {
+				next = tx.Nonce + 1
+			}
+		}
+		return next, nil
+	})
+}
@@ -0,0 +1,119 @@
+// client/errors.go
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNonceTooLow         = errors.New("octra: nonce too low")
+	ErrInsufficientBalance = errors.New("octra: insufficient balance")
+	ErrDuplicateTx         = errors.New("octra: duplicate transaction")
+	ErrInvalidSignature    = errors.New("octra: invalid signature")
+	ErrNotFound            = errors.New("octra: not found")
+	ErrRateLimited         = errors.New("octra: rate limited")
+)
+
+// RPCError is returned when the node answers with an error status or
+// explicitly rejects a request. It matches the sentinel errors above via
+// errors.Is according to the node's message and status code.
+type RPCError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Body       string
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *RPCError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Body
+	}
+	return fmt.Sprintf("rpc error [%d] %s %s: %s", e.StatusCode, e.Method, e.Endpoint, msg)
+}
+
+func (e *RPCError) Is(target error) bool {
+	return e.Kind() == target
+}
+
+// Kind returns the sentinel error describing the rejection, or nil when the
+// failure does not fall into a known class.
+func (e *RPCError) Kind() error {
+	msg := strings.ToLower(e.Message + " " + e.Body)
+	switch {
+	case strings.Contains(msg, "duplicate") || strings.Contains(msg, "already exists") ||
+		strings.Contains(msg, "already in staging") || strings.Contains(msg, "already known"):
+		return ErrDuplicateTx
+	case strings.Contains(msg, "nonce") && nonceTooLow(msg):
+		// Future or gapped nonces are not fixed by refreshing the nonce and
+		// fall through to the other classes.
+		return ErrNonceTooLow
+	case strings.Contains(msg, "insufficient"):
+		return ErrInsufficientBalance
+	case strings.Contains(msg, "signature"):
+		return ErrInvalidSignature
+	case e.StatusCode == http.StatusTooManyRequests || strings.Contains(msg, "rate limit"):
+		return ErrRateLimited
+	case e.StatusCode == http.StatusNotFound || strings.Contains(msg, "not found"):
+		return ErrNotFound
+	}
+	return nil
+}
+
+var nonceExpected = regexp.MustCompile(`expected:?\s*(\d+),?\s*got:?\s*(\d+)`)
+
+// nonceTooLow reports whether a nonce rejection means the nonce was already
+// used, as opposed to too high or leaving a gap.
+func nonceTooLow(msg string) bool {
+	for _, s := range []string{"too low", "already used", "used nonce", "expected >", "must be greater", "stale"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	if m := nonceExpected.FindStringSubmatch(msg); m != nil {
+		expected, _ := strconv.ParseUint(m[1], 10, 64)
+		got, _ := strconv.ParseUint(m[2], 10, 64)
+		return got < expected
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed if repeated later.
+func (e *RPCError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func newRPCError(status int, method, endpoint string, body []byte) *RPCError {
+	return &RPCError{
+		StatusCode: status,
+		Method:     method,
+		Endpoint:   endpoint,
+		Body:       string(body),
+		Message:    nodeMessage(body),
+	}
+}
+
+// nodeMessage extracts the human readable reason from a node error body.
+func nodeMessage(body []byte) string {
+	var payload struct {
+		Error   flexString `json:"error"`
+		Message flexString `json:"message"`
+		Detail  flexString `json:"detail"`
+		Reason  flexString `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		if msg := firstNonEmpty(string(payload.Error), string(payload.Reason), string(payload.Detail), string(payload.Message)); msg != "" {
+			return msg
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
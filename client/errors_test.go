@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRPCErrorClassification(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{400, `{"error":"invalid nonce: expected 12, got 10"}`, ErrNonceTooLow},
+		{400, `{"error":"nonce too low: expected > 4"}`, ErrNonceTooLow},
+		{400, `{"error":"insufficient balance"}`, ErrInsufficientBalance},
+		{400, `{"error":"insufficient balance for nonce 5"}`, ErrInsufficientBalance},
+		{400, `{"error":"invalid signature (nonce 3)"}`, ErrInvalidSignature},
+		{409, `{"error":"duplicate transaction"}`, ErrDuplicateTx},
+		{400, `{"error":"invalid signature"}`, ErrInvalidSignature},
+		{404, `tx not found`, ErrNotFound},
+		{429, `slow down`, ErrRateLimited},
+	}
+
+	for _, tc := range cases {
+		err := error(newRPCError(tc.status, "POST", "/send-tx", []byte(tc.body)))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("status %d body %q: expected %v, got %v", tc.status, tc.body, tc.want, err)
+		}
+	}
+
+	for _, body := range []string{
+		`{"error":"nonce too high: expected 3, got 9"}`,
+		`{"error":"invalid nonce: expected 12, got 14"}`,
+		`{"error":"nonce gap"}`,
+	} {
+		if kind := newRPCError(400, "POST", "/send-tx", []byte(body)).Kind(); kind != nil {
+			t.Errorf("body %q: expected an unclassified error, got %v", body, kind)
+		}
+	}
+
+	err := error(newRPCError(502, "GET", "/balance/oct1", []byte("bad gateway")))
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Kind() != nil || !rpcErr.Temporary() {
+		t.Errorf("502 should be an unclassified temporary RPCError, got %v", err)
+	}
+}
+
+func TestDecodeFailuresSurface(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/send-tx":
+			w.Write([]byte(`{"status":"rejected","error":"insufficient balance"}`))
+		default:
+			w.Write([]byte(`<html>maintenance</html>`))
+		}
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL)
+	if _, err := oc.GetBalance(context.Background(), "oct1"); err == nil {
+		t.Errorf("expected decode error for non-JSON balance response")
+	}
+
+	_, err := oc.SendTransaction(context.Background(), &SignedTransaction{})
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("expected ErrInsufficientBalance for rejected submission, got %v", err)
+	}
+}
@@ -4,10 +4,13 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type TransactionHistory struct {
@@ -18,6 +21,10 @@ type TransactionHistory struct {
 	Amount    string      `json:"amount"`
 	Timestamp json.Number `json:"timestamp"`
 	Status    string      `json:"status"`
+	Message   string      `json:"message,omitempty"`
+	// Confirmations counts the epochs since inclusion, including the
+	// including one; it is 0 when the node's epoch is unknown.
+	Confirmations uint64 `json:"confirmations,omitempty"`
 }
 
 type WalletStats struct {
@@ -26,73 +33,344 @@ type WalletStats struct {
 	TxCount  int
 }
 
-func (c *OctraClient) GetHistory(ctx context.Context, address string, limit int) ([]TransactionHistory, error) {
-	path := fmt.Sprintf("/address/%s?limit=%d", address, limit)
-	data, err := c.doRequest(ctx, "GET", path, nil)
-	if err != nil {
-		return nil, err
-	}
+// HistoryError reports the transactions GetHistory could not load. The
+// history returned alongside it holds every other entry.
+type HistoryError struct {
+	Failures []HistoryFailure
+}
 
-	var wrapper struct {
-		RecentTransactions []struct {
-			Hash  string `json:"hash"`
-			Epoch int    `json:"epoch"`
-		} `json:"recent_transactions"`
+// HistoryFailure is one transaction GetHistory could not load.
+type HistoryFailure struct {
+	Hash string
+	Err  error
+}
+
+func (e *HistoryError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("%s: %v", f.Hash, f.Err)
 	}
+	return fmt.Sprintf("octra: %d history transactions failed: %s", len(e.Failures), strings.Join(parts, "; "))
+}
 
-	if err := json.Unmarshal(data, &wrapper); err != nil {
-		return nil, err
+// Unwrap exposes the individual errors, so errors.Is(err, ErrNotFound) or
+// errors.Is(err, context.Canceled) match any failure.
+func (e *HistoryError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
 	}
+	return errs
+}
 
-	var history []TransactionHistory
-	for _, item := range wrapper.RecentTransactions {
-		txData, err := c.GetTransaction(ctx, item.Hash)
+// GetHistory returns the newest transactions of address, newest first. The
+// transactions are fetched on at most WithConcurrency workers. When some of
+// them fail the others are still returned, together with a *HistoryError.
+func (c *OctraClient) GetHistory(ctx context.Context, address string, limit int) ([]TransactionHistory, error) {
+	return traced(ctx, c, "GetHistory", func(ctx context.Context) ([]TransactionHistory, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetHistory")
+		defer cancel()
+		ctx = withOperation(ctx, "GetHistory")
+		recent, err := c.recentTransactions(ctx, address, limit)
 		if err != nil {
-			continue
+			return nil, err
+		}
+
+		history, histErr := c.loadHistory(ctx, recent)
+		history, err = c.applyDepth(ctx, history)
+		if err != nil {
+			return nil, err
+		}
+		if histErr != nil {
+			return history, histErr
 		}
+		return history, nil
+	})
+}
+
+// loadHistory fetches the transactions of refs concurrently and keeps their
+// order.
+func (c *OctraClient) loadHistory(ctx context.Context, refs []historyRef) ([]TransactionHistory, *HistoryError) {
+	details := make([]*TransactionDetail, len(refs))
+	errs := make([]error, len(refs))
+	c.forEach(ctx, len(refs), func(ctx context.Context, i int) {
+		details[i], errs[i] = c.GetTransaction(ctx, refs[i].Hash)
+	})
 
-		parsed, ok := txData["parsed_tx"].(map[string]interface{})
-		if !ok {
+	history := make([]TransactionHistory, 0, len(refs))
+	var histErr HistoryError
+	for i, ref := range refs {
+		tx, err := details[i], errs[i]
+		switch {
+		case err != nil:
+		case tx == nil:
+			err = ctx.Err() // not started before cancellation
+		case tx.Parsed.From == "" && tx.Parsed.To == "":
+			err = errors.New("node returned no parsed_tx")
+		}
+		if err != nil {
+			histErr.Failures = append(histErr.Failures, HistoryFailure{Hash: ref.Hash, Err: err})
 			continue
 		}
+		history = append(history, newTransactionHistory(ref.Hash, ref.Epoch, tx))
+	}
+	if len(histErr.Failures) > 0 {
+		return history, &histErr
+	}
+	return history, nil
+}
 
-		history = append(history, TransactionHistory{
-			Hash:      item.Hash,
-			Epoch:     item.Epoch,
-			From:      fmt.Sprintf("%v", parsed["from"]),
-			To:        fmt.Sprintf("%v", parsed["to"]),
-			Amount:    fmt.Sprintf("%v", parsed["amount"]),
-			Timestamp: json.Number(fmt.Sprintf("%v", parsed["timestamp"])),
-			Status:    "confirmed",
-		})
+// applyDepth fills in Confirmations and, with a WithConfirmationDepth above
+// 1, drops the entries that are not deep enough yet.
+func (c *OctraClient) applyDepth(ctx context.Context, history []TransactionHistory) ([]TransactionHistory, error) {
+	if len(history) == 0 {
+		return history, nil
+	}
+	tip, err := c.CurrentEpoch(ctx)
+	if err != nil {
+		if c.depth > 1 {
+			return nil, err
+		}
+		return history, nil
 	}
+	final := history[:0]
+	for _, h := range history {
+		h.Confirmations = Confirmations(uint64(h.Epoch), max(tip, uint64(h.Epoch)))
+		if c.depth <= 1 || h.Confirmations >= c.depth {
+			final = append(final, h)
+		}
+	}
+	return final, nil
+}
 
-	return history, nil
+// historyRef is one entry of an address's recent_transactions.
+type historyRef struct {
+	Hash  string `json:"hash"`
+	Epoch int    `json:"epoch"`
 }
 
-func (c *OctraClient) GetStats(ctx context.Context, address string) (*WalletStats, error) {
-	history, err := c.GetHistory(ctx, address, 50)
+// recentTransactions lists the newest transactions of address, newest first.
+func (c *OctraClient) recentTransactions(ctx context.Context, address string, limit int) ([]historyRef, error) {
+	return c.historyPage(ctx, address, 0, limit)
+}
+
+// historyPage lists limit transactions of address starting offset entries
+// from the newest. Nodes without history paging are asked for the first
+// offset+limit entries instead.
+func (c *OctraClient) historyPage(ctx context.Context, address string, offset, limit int) ([]historyRef, error) {
+	path := fmt.Sprintf("/address/%s?limit=%d", address, limit)
+	skip := 0
+	if offset > 0 {
+		if info, err := c.NodeInfo(ctx); err == nil && info.Features[FeatureHistoryPaging] {
+			path += fmt.Sprintf("&offset=%d", offset)
+		} else {
+			path = fmt.Sprintf("/address/%s?limit=%d", address, offset+limit)
+			skip = offset
+		}
+	}
+	data, err := c.doRequest(ctx, "GetHistory", "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
+	var wrapper struct {
+		RecentTransactions []historyRef `json:"recent_transactions"`
+	}
+	if err := decodeResponse(path, c.adapt(data), &wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper.RecentTransactions[min(skip, len(wrapper.RecentTransactions)):], nil
+}
 
-	stats := &WalletStats{
-		TotalIn:  big.NewInt(0),
-		TotalOut: big.NewInt(0),
-		TxCount:  len(history),
+func newTransactionHistory(hash string, epoch int, tx *TransactionDetail) TransactionHistory {
+	if epoch == 0 {
+		epoch = int(tx.Epoch)
 	}
+	status := tx.Status
+	if status == "" {
+		status = "confirmed"
+	}
+	return TransactionHistory{
+		Hash:      hash,
+		Epoch:     epoch,
+		From:      tx.Parsed.From,
+		To:        tx.Parsed.To,
+		Amount:    tx.Parsed.Amount,
+		Timestamp: tx.Parsed.Timestamp,
+		Status:    status,
+		Message:   tx.Message,
+	}
+}
+
+// GetStats sums all transfers of address. When some transactions
+// fail to load the stats cover the rest and the *HistoryError is returned
+// as well.
+func (c *OctraClient) GetStats(ctx context.Context, address string) (*WalletStats, error) {
+	return traced(ctx, c, "GetStats", func(ctx context.Context) (*WalletStats, error) {
+		ctx, cancel := c.withDeadline(ctx, "GetStats")
+		defer cancel()
+		var history []TransactionHistory
+		var partial *HistoryError
+		for tx, err := range c.History(withOperation(ctx, "GetStats"), address, HistoryFilter{}) {
+			var failed *HistoryError
+			switch {
+			case err == nil:
+				history = append(history, tx)
+			case errors.As(err, &failed):
+				if partial == nil {
+					partial = &HistoryError{}
+				}
+				partial.Failures = append(partial.Failures, failed.Failures...)
+			default:
+				return nil, err
+			}
+		}
+
+		stats := &WalletStats{
+			TotalIn:  big.NewInt(0),
+			TotalOut: big.NewInt(0),
+			TxCount:  len(history),
+		}
 
-	for _, tx := range history {
-		amtStr := strings.Fields(tx.Amount)[0]
-		amtFloat, _ := strconv.ParseFloat(amtStr, 64)
-		amtAtoms := ToAtoms(amtFloat)
+		for _, tx := range history {
+			amtAtoms, err := decimalToAtoms(tx.Amount)
+			if err != nil {
+				continue
+			}
+
+			if strings.EqualFold(tx.From, address) {
+				stats.TotalOut.Add(stats.TotalOut, amtAtoms)
+			} else if strings.EqualFold(tx.To, address) {
+				stats.TotalIn.Add(stats.TotalIn, amtAtoms)
+			}
+		}
 
-		if strings.EqualFold(tx.From, address) {
-			stats.TotalOut.Add(stats.TotalOut, amtAtoms)
-		} else if strings.EqualFold(tx.To, address) {
-			stats.TotalIn.Add(stats.TotalIn, amtAtoms)
+		if partial != nil {
+			return stats, partial
 		}
+		return stats, nil
+	})
+}
+
+// DefaultHistoryPageSize is the page size History requests by default.
+const DefaultHistoryPageSize = 50
+
+// HistoryFilter narrows History. Zero fields do not filter.
+type HistoryFilter struct {
+	// FromEpoch and ToEpoch bound the including epoch, inclusive. Setting
+	// either skips transactions that are not confirmed yet.
+	FromEpoch uint64
+	ToEpoch   uint64
+	// Since and Until bound the transaction timestamp, inclusive.
+	Since time.Time
+	Until time.Time
+	// Direction keeps only incoming, outgoing or self transfers.
+	Direction Direction
+	// PageSize is the number of entries requested per page.
+	PageSize int
+	// Exclude skips the hashes it returns true for without loading them,
+	// e.g. transactions already stored locally.
+	Exclude func(hash string) bool
+}
+
+func (f HistoryFilter) matchEpoch(epoch uint64) bool {
+	if f.FromEpoch == 0 && f.ToEpoch == 0 {
+		return true
 	}
+	return epoch > 0 && epoch >= f.FromEpoch && (f.ToEpoch == 0 || epoch <= f.ToEpoch)
+}
+
+func (f HistoryFilter) match(address string, h TransactionHistory) bool {
+	if f.Direction != "" && h.Direction(address) != f.Direction {
+		return false
+	}
+	if f.Since.IsZero() && f.Until.IsZero() {
+		return true
+	}
+	ts, ok := h.Time()
+	return ok && !ts.Before(f.Since) && (f.Until.IsZero() || !ts.After(f.Until))
+}
+
+// AmountAtoms returns Amount in atoms.
+func (h TransactionHistory) AmountAtoms() (*big.Int, error) {
+	return decimalToAtoms(h.Amount)
+}
+
+// Direction returns how the transaction moved funds relative to address.
+func (h TransactionHistory) Direction(address string) Direction {
+	switch {
+	case strings.EqualFold(h.From, address) && strings.EqualFold(h.To, address):
+		return DirectionSelf
+	case strings.EqualFold(h.From, address):
+		return DirectionOut
+	}
+	return DirectionIn
+}
 
-	return stats, nil
+// Time parses Timestamp as Unix seconds.
+func (h TransactionHistory) Time() (time.Time, bool) {
+	secs, err := strconv.ParseFloat(h.Timestamp.String(), 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(secs*float64(time.Second))), true
+}
+
+// History walks the entire history of address page by page, newest first,
+// yielding the transactions that match filter. A page that cannot be
+// listed yields its error and ends the walk; transactions of a page that
+// cannot be loaded yield a *HistoryError for that page and the walk goes on.
+// Breaking out of the loop stops paging.
+func (c *OctraClient) History(ctx context.Context, address string, filter HistoryFilter) iter.Seq2[TransactionHistory, error] {
+	if filter.PageSize <= 0 {
+		filter.PageSize = DefaultHistoryPageSize
+	}
+	return func(yield func(TransactionHistory, error) bool) {
+		traced(ctx, c, "History", func(ctx context.Context) (struct{}, error) {
+			ctx = withOperation(ctx, "GetHistory")
+			seen := make(map[string]bool)
+			for offset := 0; ; offset += filter.PageSize {
+				page, err := c.historyPage(ctx, address, offset, filter.PageSize)
+				if err != nil {
+					yield(TransactionHistory{}, err)
+					return struct{}{}, err
+				}
+
+				var refs []historyRef
+				older := false
+				for _, ref := range page {
+					epoch := uint64(ref.Epoch)
+					// New transactions shift offsets, so a page may repeat entries.
+					if seen[ref.Hash] {
+						continue
+					}
+					seen[ref.Hash] = true
+					if epoch > 0 && epoch < filter.FromEpoch {
+						older = true
+						break
+					}
+					if filter.matchEpoch(epoch) && (filter.Exclude == nil || !filter.Exclude(ref.Hash)) {
+						refs = append(refs, ref)
+					}
+				}
+
+				history, histErr := c.loadHistory(ctx, refs)
+				history, err = c.applyDepth(ctx, history)
+				if err != nil {
+					yield(TransactionHistory{}, err)
+					return struct{}{}, err
+				}
+				for _, h := range history {
+					if filter.match(address, h) && !yield(h, nil) {
+						return struct{}{}, nil
+					}
+				}
+				if histErr != nil && !yield(TransactionHistory{}, histErr) {
+					return struct{}{}, nil
+				}
+				if older || len(page) < filter.PageSize || ctx.Err() != nil {
+					return struct{}{}, nil
+				}
+			}
+		})
+	}
 }
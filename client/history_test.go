@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func historyServer(t *testing.T, txDelay time.Duration) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/address/"):
+			w.Write([]byte(`{"recent_transactions":[
+				{"hash":"h1","epoch":6},{"hash":"h2","epoch":5},{"hash":"h3","epoch":4},
+				{"hash":"h4","epoch":3},{"hash":"h5","epoch":2},{"hash":"h6","epoch":1}]}`))
+		case strings.HasPrefix(r.URL.Path, "/tx/"):
+			hash := strings.TrimPrefix(r.URL.Path, "/tx/")
+			// Earlier entries answer last to check that ordering is kept.
+			time.Sleep(txDelay * time.Duration(7-int(hash[1]-'0')))
+			switch hash {
+			case "h3":
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"tx not found"}`))
+			case "h5":
+				w.Write([]byte(`{"status":"confirmed","epoch":2}`))
+			default:
+				fmt.Fprintf(w, `{"status":"confirmed","parsed_tx":{"from":"octA","to":"octB","amount":"%s"}}`, hash[1:])
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetHistoryPartialFailure(t *testing.T) {
+	oc := NewClient(historyServer(t, 5*time.Millisecond).URL, WithConcurrency(3))
+	history, err := oc.GetHistory(context.Background(), "octA", 10)
+
+	var histErr *HistoryError
+	if !errors.As(err, &histErr) || len(histErr.Failures) != 2 {
+		t.Fatalf("expected a HistoryError with 2 failures, got %v", err)
+	}
+	if histErr.Failures[0].Hash != "h3" || !errors.Is(histErr.Failures[0].Err, ErrNotFound) || histErr.Failures[1].Hash != "h5" {
+		t.Errorf("unexpected failures: %+v", histErr.Failures)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected HistoryError to unwrap to ErrNotFound")
+	}
+
+	var hashes []string
+	for _, h := range history {
+		hashes = append(hashes, h.Hash)
+	}
+	if got := strings.Join(hashes, ","); got != "h1,h2,h4,h6" {
+		t.Errorf("expected ordered partial history h1,h2,h4,h6, got %s", got)
+	}
+
+	stats, err := oc.GetStats(context.Background(), "octA")
+	if !errors.As(err, &histErr) || stats == nil || stats.TxCount != 4 || stats.TotalOut.Int64() != 13_000_000 {
+		t.Errorf("expected partial stats with HistoryError, got %+v (%v)", stats, err)
+	}
+}
+
+func TestGetHistoryCancellation(t *testing.T) {
+	oc := NewClient(historyServer(t, 100*time.Millisecond).URL, WithConcurrency(2))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	history, err := oc.GetHistory(ctx, "octA", 10)
+	if !errors.Is(err, context.DeadlineExceeded) || len(history) != 0 {
+		t.Fatalf("expected deadline error and no history, got %d entries (%v)", len(history), err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("GetHistory ignored cancellation for %v", time.Since(start))
+	}
+}
+
+func TestGetHistoryDepth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/status":
+			w.Write([]byte(`{"current_epoch":3}`))
+		case strings.HasPrefix(r.URL.Path, "/address/"):
+			w.Write([]byte(`{"recent_transactions":[{"hash":"h1","epoch":0},{"hash":"h2","epoch":2},{"hash":"h3","epoch":1}]}`))
+		case r.URL.Path == "/tx/h1":
+			w.Write([]byte(`{"status":"pending","parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`))
+		default:
+			w.Write([]byte(`{"status":"confirmed","parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "h1:0,h2:2,h3:3"},
+		{[]Option{WithConfirmationDepth(1)}, "h1:0,h2:2,h3:3"},
+		{[]Option{WithConfirmationDepth(3)}, "h3:3"},
+	} {
+		history, err := NewClient(srv.URL, tc.opts...).GetHistory(context.Background(), "octA", 10)
+		if err != nil {
+			t.Fatalf("GetHistory failed: %v", err)
+		}
+		var got []string
+		for _, h := range history {
+			got = append(got, fmt.Sprintf("%s:%d", h.Hash, h.Confirmations))
+		}
+		if strings.Join(got, ",") != tc.want {
+			t.Errorf("expected %s, got %s", tc.want, strings.Join(got, ","))
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// client/metrics.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects per-operation latency histograms, error counters by
+// class, retry counts and in-flight gauges. Install it with Interceptor and
+// expose it in the Prometheus text format through ServeHTTP.
+type Metrics struct {
+	Buckets []float64
+
+	mu       sync.Mutex
+	series   map[seriesKey]*latencySeries
+	errors   map[errorKey]uint64
+	retries  map[seriesKey]uint64
+	inFlight map[string]int64
+}
+
+type seriesKey struct {
+	operation string
+	route     string
+}
+
+type errorKey struct {
+	operation string
+	class     string
+}
+
+type latencySeries struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Buckets:  DefaultLatencyBuckets,
+		series:   make(map[seriesKey]*latencySeries),
+		errors:   make(map[errorKey]uint64),
+		retries:  make(map[seriesKey]uint64),
+		inFlight: make(map[string]int64),
+	}
+}
+
+func (m *Metrics) Interceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		if call.Kind == CallMethod {
+			// Series are per RPC; the method's RPCs are observed below it.
+			return next(ctx, call)
+		}
+		m.mu.Lock()
+		m.inFlight[call.Operation]++
+		m.mu.Unlock()
+
+		start := time.Now()
+		err := next(ctx, call)
+		m.observe(call, priorAttempts(ctx), time.Since(start), err)
+		return err
+	}
+}
+
+// observe records one RPC. prior is the number of attempts an outer retry
+// loop made before this call; call.Attempts includes them, so only the
+// attempts made here are counted as retries.
+func (m *Metrics) observe(call *Call, prior int, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.inFlight[call.Operation]--
+	key := seriesKey{operation: call.Operation, route: routeOf(call.Path)}
+	s, ok := m.series[key]
+	if !ok {
+		s = &latencySeries{buckets: make([]uint64, len(m.Buckets))}
+		m.series[key] = s
+	}
+	secs := elapsed.Seconds()
+	s.count++
+	s.sum += secs
+	for i, b := range m.Buckets {
+		if secs <= b {
+			s.buckets[i]++
+		}
+	}
+	if retries := call.Attempts - max(prior, 1); retries > 0 {
+		m.retries[key] += uint64(retries)
+	}
+	if err != nil {
+		m.errors[errorKey{operation: call.Operation, class: ErrorClass(err)}]++
+	}
+}
+
+// ErrorClass maps an error to a short, low-cardinality label.
+func ErrorClass(err error) string {
+	var rpcErr *RPCError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrNonceTooLow):
+		return "nonce_too_low"
+	case errors.Is(err, ErrInsufficientBalance):
+		return "insufficient_balance"
+	case errors.Is(err, ErrDuplicateTx):
+		return "duplicate_tx"
+	case errors.Is(err, ErrInvalidSignature):
+		return "invalid_signature"
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.As(err, &rpcErr):
+		return fmt.Sprintf("http_%dxx", rpcErr.StatusCode/100)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &netErr):
+		return "network"
+	}
+	return "other"
+}
+
+// routeOf reduces a request path to its route so addresses and hashes do
+// not become label values.
+func routeOf(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	return "/" + parts[0]
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP octra_client_request_duration_seconds Latency of Octra RPC requests.\n")
+	b.WriteString("# TYPE octra_client_request_duration_seconds histogram\n")
+	for _, key := range sortedSeries(m.series) {
+		s := m.series[key]
+		labels := fmt.Sprintf(`operation="%s",route="%s"`, key.operation, key.route)
+		for i, bound := range m.Buckets {
+			fmt.Fprintf(&b, "octra_client_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(bound, 'g', -1, 64), s.buckets[i])
+		}
+		fmt.Fprintf(&b, "octra_client_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+		fmt.Fprintf(&b, "octra_client_request_duration_seconds_sum{%s} %g\n", labels, s.sum)
+		fmt.Fprintf(&b, "octra_client_request_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+
+	b.WriteString("# HELP octra_client_errors_total Failed Octra RPC requests by error class.\n")
+	b.WriteString("# TYPE octra_client_errors_total counter\n")
+	errKeys := make([]errorKey, 0, len(m.errors))
+	for k := range m.errors {
+		errKeys = append(errKeys, k)
+	}
+	sort.Slice(errKeys, func(i, j int) bool {
+		if errKeys[i].operation != errKeys[j].operation {
+			return errKeys[i].operation < errKeys[j].operation
+		}
+		return errKeys[i].class < errKeys[j].class
+	})
+	for _, k := range errKeys {
+		fmt.Fprintf(&b, "octra_client_errors_total{operation=\"%s\",class=\"%s\"} %d\n", k.operation, k.class, m.errors[k])
+	}
+
+	b.WriteString("# HELP octra_client_retries_total Retried Octra RPC attempts.\n")
+	b.WriteString("# TYPE octra_client_retries_total counter\n")
+	for _, key := range sortedSeries(m.retries) {
+		fmt.Fprintf(&b, "octra_client_retries_total{operation=\"%s\",route=\"%s\"} %d\n", key.operation, key.route, m.retries[key])
+	}
+
+	b.WriteString("# HELP octra_client_in_flight_requests Octra RPC requests currently in flight.\n")
+	b.WriteString("# TYPE octra_client_in_flight_requests gauge\n")
+	ops := make([]string, 0, len(m.inFlight))
+	for op := range m.inFlight {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		fmt.Fprintf(&b, "octra_client_in_flight_requests{operation=\"%s\"} %d\n", op, m.inFlight[op])
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func sortedSeries[V any](m map[seriesKey]V) []seriesKey {
+	keys := make([]seriesKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].route < keys[j].route
+	})
+	return keys
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetricsExposition(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/tx/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"nonce":1}`))
+	}))
+	defer srv.Close()
+
+	metrics := NewMetrics()
+	oc := NewClient(srv.URL, WithRetryPolicy(fastRetry()), WithInterceptors(metrics.Interceptor()))
+
+	oc.GetBalance(context.Background(), "oct1")
+	oc.GetTransaction(context.Background(), "deadbeef")
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+
+	for _, want := range []string{
+		`octra_client_request_duration_seconds_count{operation="GetBalance",route="/balance"} 1`,
+		`octra_client_retries_total{operation="GetBalance",route="/balance"} 1`,
+		`octra_client_errors_total{operation="GetTransaction",class="not_found"} 1`,
+		`octra_client_in_flight_requests{operation="GetBalance"} 0`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsCountSendRetriesOnce(t *testing.T) {
+	var sends int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/tx/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&sends, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"accepted","tx_hash":"abc"}`))
+	}))
+	defer srv.Close()
+
+	metrics := NewMetrics()
+	oc := NewClient(srv.URL, WithRetryPolicy(fastRetry()), WithInterceptors(metrics.Interceptor()))
+	_, _, priv, _ := GenerateNewKeyPair()
+	signed, _ := SignTransaction(Transaction{From: "octA", To: "octB", Amount: "1", Nonce: 1, Timestamp: json.Number("1")}, priv)
+	if _, err := oc.SendTransaction(context.Background(), signed); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	want := `octra_client_retries_total{operation="SendTransaction",route="/send-tx"} 2`
+	if out := rec.Body.String(); !strings.Contains(out, want) {
+		t.Errorf("metrics output missing %q:\n%s", want, out)
+	}
+}
@@ -0,0 +1,223 @@
+// client/middleware.go
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// CallKind tells the calls interceptors see apart.
+type CallKind int
+
+const (
+	// CallRPC is a single request to the node.
+	CallRPC CallKind = iota
+	// CallMethod is a public OctraClient method, seen once per invocation
+	// even when it is answered from the cache or shares a coalesced
+	// request. Method, Path, Request, Response and Attempts are empty.
+	CallMethod
+)
+
+// Call describes an OctraClient method or a single RPC as seen by
+// interceptors. Operation is the public method the caller invoked; RPCs
+// issued on its behalf (such as the /tx lookups behind GetHistory) carry the
+// same Operation and have the method's call as Parent. Interceptors run for
+// the method first, so the context they pass on (a trace span, a request ID)
+// reaches its RPCs.
+type Call struct {
+	Kind      CallKind
+	Parent    *Call
+	Operation string
+	Method    string
+	Path      string
+	Request   interface{}
+	Header    http.Header
+	Response  []byte
+	Attempts  int
+	Duration  time.Duration
+	Err       error
+}
+
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor wraps an RPC. It must call next to perform the request and may
+// inspect or modify call before and after doing so.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+type operationKey struct{}
+type requestIDKey struct{}
+
+func withOperation(ctx context.Context, op string) context.Context {
+	if _, ok := ctx.Value(operationKey{}).(string); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+func operationFrom(ctx context.Context, fallback string) string {
+	if op, ok := ctx.Value(operationKey{}).(string); ok {
+		return op
+	}
+	return fallback
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+type methodCallKey struct{}
+
+// traced runs fn as the public method op, wrapped in one CallMethod call.
+// Public methods used by another one run inside the outer call.
+func traced[T any](ctx context.Context, c *OctraClient, op string, fn func(context.Context) (T, error)) (T, error) {
+	if len(c.interceptors) == 0 || methodCallFrom(ctx) != nil {
+		return fn(ctx)
+	}
+	var res T
+	call := &Call{Kind: CallMethod, Operation: op}
+	err := c.chain(ctx, call, func(ctx context.Context, call *Call) error {
+		var err error
+		res, err = fn(context.WithValue(ctx, methodCallKey{}, call))
+		call.Err = err
+		return err
+	})
+	return res, err
+}
+
+func methodCallFrom(ctx context.Context) *Call {
+	call, _ := ctx.Value(methodCallKey{}).(*Call)
+	return call
+}
+
+// chain runs call through the client's interceptors, outermost first.
+// call.Duration is set as soon as final returns, so interceptors see it on
+// their way out.
+func (c *OctraClient) chain(ctx context.Context, call *Call, final Invoker) error {
+	start := time.Now()
+	next := func(ctx context.Context, call *Call) error {
+		err := final(ctx, call)
+		call.Duration = time.Since(start)
+		return err
+	}
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		ic, inner := c.interceptors[i], next
+		next = func(ctx context.Context, call *Call) error { return ic(ctx, call, inner) }
+	}
+	err := next(ctx, call)
+	if call.Duration == 0 {
+		call.Duration = time.Since(start)
+	}
+	return err
+}
+
+// RequestIDInterceptor sends the context's request ID (generating one when
+// missing) in the given header, "X-Request-ID" by default. All RPCs of one
+// method call share an ID.
+func RequestIDInterceptor(header string) Interceptor {
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		id := RequestIDFromContext(ctx)
+		if id == "" {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+			ctx = WithRequestID(ctx, id)
+		}
+		if call.Kind == CallMethod {
+			return next(ctx, call)
+		}
+		if call.Header == nil {
+			call.Header = make(http.Header)
+		}
+		call.Header.Set(header, id)
+		return next(ctx, call)
+	}
+}
+
+// LoggingInterceptor logs every RPC with log/slog, and every method call at
+// debug level. Signatures, keys and other secret material are redacted from
+// logged bodies.
+func LoggingInterceptor(logger *slog.Logger) Interceptor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		start := time.Now()
+		err := next(ctx, call)
+		if call.Kind == CallMethod {
+			attrs := []slog.Attr{
+				slog.String("operation", call.Operation),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", Redact(err.Error())))
+			}
+			logger.LogAttrs(ctx, slog.LevelDebug, "octra call", attrs...)
+			return err
+		}
+		attrs := []slog.Attr{
+			slog.String("operation", call.Operation),
+			slog.String("method", call.Method),
+			slog.String("path", call.Path),
+			slog.Duration("duration", time.Since(start)),
+			slog.Int("attempts", call.Attempts),
+		}
+		if id := RequestIDFromContext(ctx); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		} else if call.Header != nil && call.Header.Get("X-Request-ID") != "" {
+			attrs = append(attrs, slog.String("request_id", call.Header.Get("X-Request-ID")))
+		}
+		if call.Request != nil {
+			body, _ := json.Marshal(call.Request)
+			attrs = append(attrs, slog.String("request", Redact(string(body))))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", Redact(err.Error())))
+			logger.LogAttrs(ctx, slog.LevelWarn, "octra rpc failed", attrs...)
+			return err
+		}
+		attrs = append(attrs, slog.String("response", Redact(string(call.Response))))
+		logger.LogAttrs(ctx, slog.LevelDebug, "octra rpc", attrs...)
+		return nil
+	}
+}
+
+// secretPattern matches secret fields both in a JSON document and in JSON
+// embedded as a string, where the quotes are escaped.
+var secretPattern = regexp.MustCompile(`(\\*")(signature|public_key|private_key|priv|seed|password|ciphertext)\\*"\s*:\s*\\*"(?:[^"\\]|\\[^"])*\\*"`)
+
+// Redact masks signatures and private material in a JSON document.
+func Redact(s string) string {
+	return secretPattern.ReplaceAllString(s, `${1}${2}${1}:${1}[REDACTED]${1}`)
+}
+
+// Tracer adapts OctraClient calls to a tracing system.
+type Tracer interface {
+	Start(ctx context.Context, call *Call) (context.Context, Span)
+}
+
+type Span interface {
+	End(call *Call)
+}
+
+func TracingInterceptor(tracer Tracer) Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		ctx, span := tracer.Start(ctx, call)
+		err := next(ctx, call)
+		span.End(call)
+		return err
+	}
+}
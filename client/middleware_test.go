@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInterceptorChain(t *testing.T) {
+	var gotRequestID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotRequestID = r.Header.Get("X-Request-ID")
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{"status":"accepted","tx_hash":"abc"}`))
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	var ops []string
+	var calls []*Call
+	var durations []time.Duration
+	oc := NewClient(srv.URL, WithInterceptors(
+		RequestIDInterceptor(""),
+		LoggingInterceptor(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		func(ctx context.Context, call *Call, next Invoker) error {
+			ops = append(ops, strings.TrimSpace(call.Operation+" "+call.Method+" "+call.Path))
+			calls = append(calls, call)
+			err := next(ctx, call)
+			durations = append(durations, call.Duration)
+			return err
+		},
+	))
+
+	_, _, priv, _ := GenerateNewKeyPair()
+	signed, _ := SignTransaction(Transaction{From: "octA", To: "octB", Amount: "1", Nonce: 1, Timestamp: json.Number("1")}, priv)
+	ctx := WithRequestID(context.Background(), "req-42")
+	if _, err := oc.SendTransaction(ctx, signed); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if gotRequestID != "req-42" {
+		t.Errorf("request ID not propagated, got %q", gotRequestID)
+	}
+	if len(ops) != 2 || ops[0] != "SendTransaction" || ops[1] != "SendTransaction POST /send-tx" {
+		t.Fatalf("unexpected calls seen by interceptor: %v", ops)
+	}
+	for i, d := range durations {
+		if d < 5*time.Millisecond {
+			t.Errorf("call %d: interceptor saw duration %v on its way out", i, d)
+		}
+	}
+	if calls[0].Kind != CallMethod || calls[1].Kind != CallRPC || calls[1].Parent != calls[0] {
+		t.Errorf("RPC must be nested under its method call: %+v", calls)
+	}
+	if strings.Contains(logs.String(), signed.Signature) || !strings.Contains(logs.String(), "[REDACTED]") {
+		t.Errorf("signature must be redacted from logs: %s", logs.String())
+	}
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []string
+}
+
+type spanKey struct{}
+
+type recordingSpan struct {
+	tracer *recordingTracer
+	parent string
+}
+
+func (r *recordingTracer) Start(ctx context.Context, call *Call) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(string)
+	name := call.Operation
+	if call.Kind == CallRPC {
+		name += " " + call.Path
+	}
+	return context.WithValue(ctx, spanKey{}, name), &recordingSpan{tracer: r, parent: parent}
+}
+
+func (s *recordingSpan) End(call *Call) {
+	name := call.Operation
+	if call.Kind == CallRPC {
+		name += " " + call.Path
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.spans = append(s.tracer.spans, s.parent+" > "+name)
+}
+
+func TestMethodLevelCalls(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/address/"):
+			w.Write([]byte(`{"recent_transactions":[{"hash":"h1","epoch":2},{"hash":"h2","epoch":1}]}`))
+		case strings.HasPrefix(r.URL.Path, "/tx/"):
+			w.Write([]byte(`{"status":"confirmed","epoch":1,"parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`))
+		default:
+			w.Write([]byte(`{"balance":"1.5","nonce":2}`))
+		}
+	}))
+	defer srv.Close()
+
+	tracer := &recordingTracer{}
+	oc := NewClient(srv.URL, WithInterceptors(TracingInterceptor(tracer)), WithCache(NewLRUCache(16)))
+	ctx := context.Background()
+
+	// Every RPC behind GetHistory is a child of one GetHistory span.
+	if _, err := oc.GetHistory(ctx, "octA", 10); err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	roots, lookups := 0, 0
+	for _, span := range tracer.spans {
+		switch {
+		case span == " > GetHistory":
+			roots++
+		case !strings.HasPrefix(span, "GetHistory > GetHistory /"):
+			t.Errorf("span %q is not nested under GetHistory", span)
+		case strings.Contains(span, "/tx/"):
+			lookups++
+		}
+	}
+	if roots != 1 || lookups != 2 {
+		t.Errorf("expected one GetHistory span over 2 lookups, got %q", tracer.spans)
+	}
+
+	// Cache hits still show up as method calls without RPCs.
+	tracer.spans = nil
+	oc.GetBalance(ctx, "octA")
+	before := atomic.LoadInt32(&hits)
+	oc.GetBalance(ctx, "octA")
+	if atomic.LoadInt32(&hits) != before {
+		t.Fatal("second GetBalance should be served from the cache")
+	}
+	want := []string{"GetBalance > GetBalance /balance/octA", " > GetBalance", " > GetBalance"}
+	if !slices.Equal(tracer.spans, want) {
+		t.Errorf("expected spans %q, got %q", want, tracer.spans)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	cases := map[string]string{
+		`{"signature":"SECRET","amount":"1"}`:     `{"signature":"[REDACTED]","amount":"1"}`,
+		`{"data":"{\"signature\":\"SECRET\"}"}`:   `{"data":"{\"signature\":\"[REDACTED]\"}"}`,
+		`{"priv" : "SEC\nRET", "to":"octB"}`:      `{"priv":"[REDACTED]", "to":"octB"}`,
+		`{"message":"signature is not a secret"}`: `{"message":"signature is not a secret"}`,
+	}
+	for in, want := range cases {
+		if got := Redact(in); got != want {
+			t.Errorf("Redact(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
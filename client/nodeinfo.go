@@ -0,0 +1,218 @@
+// client/nodeinfo.go
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Feature names an optional node capability.
+type Feature string
+
+const (
+	FeatureStatus           Feature = "status"
+	FeatureStaging          Feature = "staging"
+	FeatureEpochs           Feature = "epochs"
+	FeatureEncryptedBalance Feature = "encrypted_balance"
+	FeatureBatchBalance     Feature = "batch_balance"
+	FeatureEpochStream      Feature = "epoch_stream"
+	FeatureHistoryPaging    Feature = "history_paging"
+)
+
+var ErrUnsupported = errors.New("octra: not supported by node")
+
+// NodeInfo describes what a node build offers, as discovered by Probe.
+// Fields maps node-specific response field names to the names this SDK
+// decodes (e.g. "recipient" -> "to").
+type NodeInfo struct {
+	Version  string
+	Network  string
+	Epoch    uint64
+	Features map[Feature]bool
+	Fields   map[string]string
+}
+
+// Supports reports whether the node offers f. Features are assumed present
+// on nodes that could not be probed at all, so old builds keep working.
+func (n *NodeInfo) Supports(f Feature) bool {
+	if n == nil || n.Features == nil {
+		return true
+	}
+	return n.Features[f]
+}
+
+// probeState caches the probed NodeInfo. Readers load it without locking;
+// concurrent first uses share one probe, which runs without holding mu.
+type probeState struct {
+	info atomic.Pointer[NodeInfo]
+
+	mu   sync.Mutex
+	call *probeCall
+}
+
+type probeCall struct {
+	done chan struct{}
+	info *NodeInfo
+	err  error
+}
+
+// probeRoutes are checked directly when /status does not list features.
+var probeRoutes = map[Feature]string{
+	FeatureStaging:          "/staging",
+	FeatureEncryptedBalance: "/view_encrypted_balance/oct1",
+	FeatureEpochs:           "/epoch/1",
+}
+
+// NodeInfo returns the node's capabilities, probing it on first use.
+func (c *OctraClient) NodeInfo(ctx context.Context) (*NodeInfo, error) {
+	return traced(ctx, c, "NodeInfo", func(ctx context.Context) (*NodeInfo, error) {
+		if info := c.probe.info.Load(); info != nil {
+			return info, nil
+		}
+		c.probe.mu.Lock()
+		call := c.probe.call
+		if call == nil {
+			call = &probeCall{done: make(chan struct{})}
+			c.probe.call = call
+			c.probe.mu.Unlock()
+
+			call.info, call.err = c.probeNode(ctx)
+			c.probe.mu.Lock()
+			if call.err == nil {
+				c.probe.info.Store(call.info)
+			}
+			c.probe.call = nil
+			c.probe.mu.Unlock()
+			close(call.done)
+			return call.info, call.err
+		}
+		c.probe.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.info, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+}
+
+// Probe discards any cached NodeInfo and probes the node again.
+func (c *OctraClient) Probe(ctx context.Context) (*NodeInfo, error) {
+	return traced(ctx, c, "Probe", func(ctx context.Context) (*NodeInfo, error) {
+		c.probe.info.Store(nil)
+		return c.NodeInfo(ctx)
+	})
+}
+
+func (c *OctraClient) probeNode(ctx context.Context) (*NodeInfo, error) {
+	ctx = withOperation(ctx, "Probe")
+	info := &NodeInfo{Features: make(map[Feature]bool), Fields: make(map[string]string)}
+
+	data, err := c.doRequest(ctx, "Probe", "GET", "/status", nil)
+	var rpcErr *RPCError
+	switch {
+	case err == nil:
+		var status struct {
+			Version      flexString        `json:"version"`
+			Network      flexString        `json:"network"`
+			Epoch        flexUint          `json:"epoch"`
+			CurrentEpoch flexUint          `json:"current_epoch"`
+			Features     []string          `json:"features"`
+			Fields       map[string]string `json:"fields"`
+		}
+		if err := decodeResponse("/status", data, &status); err != nil {
+			return nil, err
+		}
+		info.Version = string(status.Version)
+		info.Network = string(status.Network)
+		info.Epoch = uint64(max(status.Epoch, status.CurrentEpoch))
+		info.Features[FeatureStatus] = true
+		for _, f := range status.Features {
+			info.Features[Feature(f)] = true
+		}
+		for nodeName, sdkName := range status.Fields {
+			info.Fields[nodeName] = sdkName
+		}
+		if len(status.Features) > 0 {
+			return info, nil
+		}
+	case errors.As(err, &rpcErr) && !rpcErr.Temporary():
+		// No status route on this build; fall through to route probing.
+	default:
+		return nil, err
+	}
+
+	for feature, route := range probeRoutes {
+		_, err := c.doRequest(ctx, "Probe", "GET", route, nil)
+		info.Features[feature] = err == nil || (errors.As(err, &rpcErr) && routeExists(rpcErr))
+	}
+	return info, nil
+}
+
+// routeExists tells a missing route apart from a missing resource: node
+// handlers answer with a JSON error body, the router with plain text.
+func routeExists(e *RPCError) bool {
+	if e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusMethodNotAllowed {
+		return json.Valid([]byte(e.Body)) && bytes.HasPrefix(bytes.TrimSpace([]byte(e.Body)), []byte("{"))
+	}
+	return !e.Temporary()
+}
+
+// requireFeature returns ErrUnsupported when the probed node lacks f.
+func (c *OctraClient) requireFeature(ctx context.Context, f Feature) error {
+	info, err := c.NodeInfo(ctx)
+	if err != nil {
+		return err
+	}
+	if !info.Supports(f) {
+		return fmt.Errorf("%w: %s (node version %q)", ErrUnsupported, f, info.Version)
+	}
+	return nil
+}
+
+// adapt renames node-specific field names to the ones the SDK decodes. It
+// only applies once the node has been probed.
+func (c *OctraClient) adapt(data []byte) []byte {
+	info := c.probe.info.Load()
+	if info == nil || len(info.Fields) == 0 {
+		return data
+	}
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if dec.Decode(&doc) != nil {
+		return data
+	}
+	out, err := json.Marshal(renameFields(doc, info.Fields))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func renameFields(v interface{}, fields map[string]string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			if name, ok := fields[k]; ok {
+				k = name
+			}
+			out[k] = renameFields(val, fields)
+		}
+		return out
+	case []interface{}:
+		for i := range t {
+			t[i] = renameFields(t[i], fields)
+		}
+		return t
+	}
+	return v
+}
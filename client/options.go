@@ -0,0 +1,270 @@
+// client/options.go
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Option configures an OctraClient in NewClient. Options are applied once;
+// the resulting client is immutable and safe for concurrent use.
+type Option func(*clientConfig)
+
+type clientConfig struct {
+	timeout      time.Duration
+	httpClient   *http.Client
+	transport    http.RoundTripper
+	proxy        *url.URL
+	rootCAs      *x509.CertPool
+	pins         []string
+	headers      http.Header
+	retry        RetryPolicy
+	endpoints    *EndpointPool
+	interceptors []Interceptor
+	deadlines    map[string]time.Duration
+	limiter      *RateLimiter
+	cache        Cache
+	balanceTTL   time.Duration
+	coalesce     bool
+	concurrency  int
+	depth        uint64
+	pollInterval time.Duration
+}
+
+// WithTimeout sets the overall HTTP timeout per request (default 30s).
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *clientConfig) { cfg.timeout = d }
+}
+
+// WithHTTPClient uses hc for all requests. WithProxy, WithRootCAs and
+// WithPinnedCertificates are applied to a copy of it and need its Transport
+// to be an *http.Transport (or nil).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(cfg *clientConfig) { cfg.httpClient = hc }
+}
+
+// WithTransport sets the base RoundTripper, e.g. a test transport. WithProxy,
+// WithRootCAs and WithPinnedCertificates need it to be an *http.Transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(cfg *clientConfig) { cfg.transport = rt }
+}
+
+func WithProxy(proxy *url.URL) Option {
+	return func(cfg *clientConfig) { cfg.proxy = proxy }
+}
+
+// WithRootCAs trusts the given pool instead of the system roots.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(cfg *clientConfig) { cfg.rootCAs = pool }
+}
+
+// WithPinnedCertificates only accepts server chains containing a certificate
+// whose SubjectPublicKeyInfo SHA-256 matches one of the hex fingerprints.
+func WithPinnedCertificates(fingerprints ...string) Option {
+	return func(cfg *clientConfig) {
+		for _, fp := range fingerprints {
+			cfg.pins = append(cfg.pins, strings.ToLower(strings.ReplaceAll(fp, ":", "")))
+		}
+	}
+}
+
+func WithHeader(key, value string) Option {
+	return func(cfg *clientConfig) { cfg.headers.Set(key, value) }
+}
+
+// WithAPIKey sends key in header ("X-API-Key" when header is empty).
+func WithAPIKey(header, key string) Option {
+	if header == "" {
+		header = "X-API-Key"
+	}
+	return WithHeader(header, key)
+}
+
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+func WithUserAgent(ua string) Option {
+	return WithHeader("User-Agent", ua)
+}
+
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(cfg *clientConfig) { cfg.retry = p }
+}
+
+// WithEndpointPool routes requests over several nodes, see NewMultiClient.
+func WithEndpointPool(pool *EndpointPool) Option {
+	return func(cfg *clientConfig) { cfg.endpoints = pool }
+}
+
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(cfg *clientConfig) { cfg.interceptors = append(cfg.interceptors, interceptors...) }
+}
+
+// WithMethodTimeout applies a default deadline to calls of the named
+// operation (e.g. "GetBalance") when the caller's context has none.
+func WithMethodTimeout(operation string, d time.Duration) Option {
+	return func(cfg *clientConfig) { cfg.deadlines[operation] = d }
+}
+
+// WithRateLimit caps the client at rps requests per second with the given
+// burst, shared by all operations.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(cfg *clientConfig) { cfg.rateLimiter().setGlobal(rps, burst) }
+}
+
+// WithPathRateLimit adds a limit for requests whose path starts with prefix.
+func WithPathRateLimit(prefix string, rps float64, burst int) Option {
+	return func(cfg *clientConfig) { cfg.rateLimiter().SetPathLimit(prefix, rps, burst) }
+}
+
+// WithRateLimiter shares an existing limiter, e.g. between several clients
+// hitting the same provider.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(cfg *clientConfig) { cfg.limiter = l }
+}
+
+// WithCache caches confirmed transactions permanently and balances for the
+// balance TTL, and coalesces concurrent identical lookups.
+func WithCache(cache Cache) Option {
+	return func(cfg *clientConfig) {
+		cfg.cache = cache
+		cfg.coalesce = true
+	}
+}
+
+// WithBalanceTTL sets how long balances are cached (default
+// DefaultBalanceTTL). A zero or negative d turns balance caching off.
+func WithBalanceTTL(d time.Duration) Option {
+	return func(cfg *clientConfig) { cfg.balanceTTL = d }
+}
+
+// WithRequestCoalescing merges concurrent identical GET requests into a
+// single RPC without caching the result.
+func WithRequestCoalescing() Option {
+	return func(cfg *clientConfig) { cfg.coalesce = true }
+}
+
+// WithConcurrency bounds how many RPCs fan-out helpers such as GetBalances
+// run at once (default DefaultConcurrency).
+func WithConcurrency(n int) Option {
+	return func(cfg *clientConfig) {
+		if n > 0 {
+			cfg.concurrency = n
+		}
+	}
+}
+
+// WithConfirmationDepth makes WaitTransaction and the history APIs treat a
+// transaction as final only once n epochs, counting the including one,
+// exist. The default of 1 accepts a transaction on inclusion and leaves the
+// history unfiltered, pending entries included; a depth above 1 leaves out
+// every entry with fewer confirmations, pending ones too.
+func WithConfirmationDepth(n uint64) Option {
+	return func(cfg *clientConfig) {
+		if n > 0 {
+			cfg.depth = n
+		}
+	}
+}
+
+// WithPollInterval sets how often SubscribeEpochs polls nodes without an
+// epoch stream (default DefaultPollInterval).
+func WithPollInterval(d time.Duration) Option {
+	return func(cfg *clientConfig) {
+		if d > 0 {
+			cfg.pollInterval = d
+		}
+	}
+}
+
+func (cfg *clientConfig) rateLimiter() *RateLimiter {
+	if cfg.limiter == nil {
+		cfg.limiter = NewRateLimiter(0, 0)
+	}
+	return cfg.limiter
+}
+
+// ErrTransportOptions is returned by every request of a client whose proxy,
+// root CA or pinning options could not be applied to its transport.
+var ErrTransportOptions = errors.New("octra: proxy, root CA and pinning options need an *http.Transport")
+
+// brokenTransport fails every request. NewClient cannot return an error, so a
+// client whose transport options cannot be honoured fails on first use rather
+// than silently connecting without them.
+type brokenTransport struct{ err error }
+
+func (b brokenTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, b.err
+}
+
+func (cfg *clientConfig) buildHTTPClient() *http.Client {
+	hc := &http.Client{Timeout: cfg.timeout, Transport: cfg.transport}
+	if cfg.httpClient != nil {
+		hc = cfg.httpClient
+	}
+	if cfg.proxy == nil && cfg.rootCAs == nil && len(cfg.pins) == 0 {
+		return hc
+	}
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	// Never modify the caller's client or transport.
+	copied := *hc
+	hc = &copied
+	if base, ok := rt.(*http.Transport); !ok {
+		hc.Transport = brokenTransport{fmt.Errorf("%w, got %T", ErrTransportOptions, rt)}
+	} else {
+		t := base.Clone()
+		if cfg.proxy != nil {
+			t.Proxy = http.ProxyURL(cfg.proxy)
+		}
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		if cfg.rootCAs != nil {
+			t.TLSClientConfig.RootCAs = cfg.rootCAs
+		}
+		if len(cfg.pins) > 0 {
+			t.TLSClientConfig.VerifyConnection = verifyPins(cfg.pins)
+		}
+		hc.Transport = t
+	}
+	return hc
+}
+
+var ErrCertificatePin = errors.New("octra: server certificate does not match pinned key")
+
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		for _, cert := range cs.PeerCertificates {
+			sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+			fp := hex.EncodeToString(sum[:])
+			for _, pin := range pins {
+				if fp == pin {
+					return nil
+				}
+			}
+		}
+		return ErrCertificatePin
+	}
+}
+
+// withDeadline applies the configured default deadline for op.
+func (c *OctraClient) withDeadline(ctx context.Context, op string) (context.Context, context.CancelFunc) {
+	d, ok := c.deadlines[op]
+	if _, has := ctx.Deadline(); !ok || has || d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("User-Agent") != "octra-test/1.0" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/tx/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(`{"nonce":1}`))
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL,
+		WithBearerToken("secret"),
+		WithUserAgent("octra-test/1.0"),
+		WithMethodTimeout("GetTransaction", 20*time.Millisecond),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+	)
+	if _, err := oc.GetBalance(context.Background(), "oct1"); err != nil {
+		t.Fatalf("auth headers not applied: %v", err)
+	}
+	if _, err := oc.GetTransaction(context.Background(), "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected per-method deadline to fire, got %v", err)
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	tlsSrv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"nonce":1}`))
+	}))
+	defer tlsSrv.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(tlsSrv.Certificate())
+	sum := sha256.Sum256(tlsSrv.Certificate().RawSubjectPublicKeyInfo)
+	pin := hex.EncodeToString(sum[:])
+	ctx := context.Background()
+	once := WithRetryPolicy(RetryPolicy{MaxAttempts: 1})
+
+	if _, err := NewClient(tlsSrv.URL, once).GetBalance(ctx, "oct1"); err == nil {
+		t.Error("expected the test certificate to be rejected by the system roots")
+	}
+	if _, err := NewClient(tlsSrv.URL, once, WithRootCAs(pool)).GetBalance(ctx, "oct1"); err != nil {
+		t.Errorf("WithRootCAs not applied: %v", err)
+	}
+	if _, err := NewClient(tlsSrv.URL, once, WithRootCAs(pool), WithPinnedCertificates(pin)).GetBalance(ctx, "oct1"); err != nil {
+		t.Errorf("matching pin rejected: %v", err)
+	}
+	if _, err := NewClient(tlsSrv.URL, once, WithRootCAs(pool), WithPinnedCertificates(strings.Repeat("00", 32))).GetBalance(ctx, "oct1"); !errors.Is(err, ErrCertificatePin) {
+		t.Errorf("expected ErrCertificatePin, got %v", err)
+	}
+
+	// Options are applied to a copy of a caller supplied client.
+	own := &http.Client{}
+	if _, err := NewClient(tlsSrv.URL, once, WithHTTPClient(own), WithRootCAs(pool)).GetBalance(ctx, "oct1"); err != nil {
+		t.Errorf("WithRootCAs not applied to WithHTTPClient: %v", err)
+	}
+	if own.Transport != nil {
+		t.Error("the caller's client must not be modified")
+	}
+
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.Write([]byte(`{"nonce":1}`))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	if _, err := NewClient("http://node.invalid", once, WithProxy(proxyURL)).GetBalance(ctx, "oct1"); err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	if proxied != "http://node.invalid/balance/oct1" {
+		t.Errorf("proxy saw %q", proxied)
+	}
+
+	// A transport the options cannot be applied to fails every request.
+	custom := &failingTransport{}
+	if _, err := NewClient(tlsSrv.URL, once, WithTransport(custom), WithRootCAs(pool)).GetBalance(ctx, "oct1"); !errors.Is(err, ErrTransportOptions) {
+		t.Errorf("expected ErrTransportOptions, got %v", err)
+	}
+	if custom.calls != 0 {
+		t.Error("the transport must not be used without the requested options")
+	}
+}
@@ -0,0 +1,142 @@
+// client/quorum.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ErrNoQuorum = errors.New("octra: no quorum")
+
+// QuorumClient answers reads only when at least Required of its nodes
+// return the same value.
+type QuorumClient struct {
+	Nodes    []*OctraClient
+	Required int
+}
+
+// NodeAnswer is what a single node returned during a quorum read.
+type NodeAnswer struct {
+	URL   string
+	Value string
+	Err   error
+}
+
+// QuorumError lists every node's answer when no value reached the quorum.
+type QuorumError struct {
+	Op       string
+	Required int
+	Answers  []NodeAnswer
+}
+
+func (e *QuorumError) Error() string {
+	parts := make([]string, 0, len(e.Answers))
+	for _, a := range e.Answers {
+		if a.Err != nil {
+			parts = append(parts, fmt.Sprintf("%s: error: %v", a.URL, a.Err))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %s", a.URL, a.Value))
+		}
+	}
+	return fmt.Sprintf("%s: no quorum (%d of %d required): %s", e.Op, e.Required, len(e.Answers), strings.Join(parts, "; "))
+}
+
+func (e *QuorumError) Is(target error) bool {
+	return target == ErrNoQuorum
+}
+
+// NewQuorumClient creates one client per url. Caching and request
+// coalescing are turned off for them: a shared cache would let one node's
+// answer count as every node's vote.
+func NewQuorumClient(urls []string, required int, opts ...Option) *QuorumClient {
+	q := &QuorumClient{Required: required}
+	opts = append(slices.Clone(opts), withoutCache())
+	for _, u := range urls {
+		q.Nodes = append(q.Nodes, NewClient(u, opts...))
+	}
+	return q
+}
+
+func (q *QuorumClient) GetBalance(ctx context.Context, address string) (*BalanceInfo, error) {
+	return quorumRead(ctx, q, "GetBalance",
+		func(ctx context.Context, c *OctraClient) (*BalanceInfo, error) {
+			return c.GetBalance(ctx, address)
+		},
+		func(b *BalanceInfo) string {
+			return fmt.Sprintf("balance=%s nonce=%d", firstNonEmpty(b.BalanceRaw, b.Balance), b.Nonce)
+		})
+}
+
+func (q *QuorumClient) GetTransaction(ctx context.Context, hash string) (*TransactionDetail, error) {
+	return quorumRead(ctx, q, "GetTransaction",
+		func(ctx context.Context, c *OctraClient) (*TransactionDetail, error) {
+			return c.GetTransaction(ctx, hash)
+		},
+		func(tx *TransactionDetail) string {
+			return fmt.Sprintf("confirmed=%t epoch=%d from=%s to=%s amount=%s nonce=%d",
+				tx.Confirmed(), tx.Epoch, tx.Parsed.From, tx.Parsed.To, tx.Parsed.Amount, tx.Parsed.Nonce)
+		})
+}
+
+func withoutCache() Option {
+	return func(cfg *clientConfig) {
+		cfg.cache = nil
+		cfg.coalesce = false
+	}
+}
+
+const notFoundAnswer = "not found"
+
+// quorumRead queries every node in parallel and returns as soon as Required
+// of them agree on the same key. Nodes agreeing that an object does not exist
+// form a quorum too, reported as ErrNotFound.
+func quorumRead[T any](ctx context.Context, q *QuorumClient, op string,
+	fetch func(context.Context, *OctraClient) (T, error), key func(T) string) (T, error) {
+	var zero T
+	required := q.Required
+	if required < 1 {
+		required = len(q.Nodes)/2 + 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		answer NodeAnswer
+		value  T
+	}
+	results := make(chan result, len(q.Nodes))
+	for _, node := range q.Nodes {
+		go func(node *OctraClient) {
+			v, err := fetch(ctx, node)
+			a := NodeAnswer{URL: node.BaseURL(), Err: err}
+			if err == nil {
+				a.Value = key(v)
+			} else if errors.Is(err, ErrNotFound) {
+				a.Value, a.Err = notFoundAnswer, nil
+			}
+			results <- result{answer: a, value: v}
+		}(node)
+	}
+
+	votes := make(map[string]int)
+	answers := make([]NodeAnswer, 0, len(q.Nodes))
+	for range q.Nodes {
+		r := <-results
+		answers = append(answers, r.answer)
+		if r.answer.Err != nil {
+			continue
+		}
+		votes[r.answer.Value]++
+		if votes[r.answer.Value] >= required {
+			if r.answer.Value == notFoundAnswer {
+				return zero, fmt.Errorf("%s: %d nodes agree: %w", op, required, ErrNotFound)
+			}
+			return r.value, nil
+		}
+	}
+	return zero, &QuorumError{Op: op, Required: required, Answers: answers}
+}
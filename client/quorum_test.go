@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func balanceNode(body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+}
+
+func TestQuorumBalance(t *testing.T) {
+	a := balanceNode(`{"balance_raw":"5000000","nonce":4}`)
+	b := balanceNode(`{"balance_raw":"5000000","nonce":4}`)
+	c := balanceNode(`{"balance_raw":"9000000","nonce":4}`)
+	defer a.Close()
+	defer b.Close()
+	defer c.Close()
+
+	q := NewQuorumClient([]string{a.URL, b.URL, c.URL}, 2)
+	info, err := q.GetBalance(context.Background(), "oct1")
+	if err != nil || info.BalanceRaw != "5000000" {
+		t.Fatalf("expected agreed balance, got %+v (%v)", info, err)
+	}
+
+	q.Required = 3
+	_, err = q.GetBalance(context.Background(), "oct1")
+	var qErr *QuorumError
+	if !errors.Is(err, ErrNoQuorum) || !errors.As(err, &qErr) || len(qErr.Answers) != 3 {
+		t.Fatalf("expected QuorumError with all answers, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "balance=9000000") {
+		t.Errorf("disagreement should name the dissenting value: %v", err)
+	}
+}
+
+func TestQuorumIgnoresSharedCache(t *testing.T) {
+	a := balanceNode(`{"balance_raw":"5000000","nonce":4}`)
+	b := balanceNode(`{"balance_raw":"9000000","nonce":4}`)
+	c := balanceNode(`{"balance_raw":"7000000","nonce":4}`)
+	defer a.Close()
+	defer b.Close()
+	defer c.Close()
+
+	q := NewQuorumClient([]string{a.URL, b.URL, c.URL}, 2, WithCache(NewLRUCache(16)))
+	for i := 0; i < 2; i++ {
+		if _, err := q.GetBalance(context.Background(), "oct1"); !errors.Is(err, ErrNoQuorum) {
+			t.Fatalf("read %d: expected no quorum from disagreeing nodes, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// client/ratelimit.go
+package client
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token-bucket limiter with a global bucket and optional
+// per-path buckets. When the node answers 429 the affected buckets halve
+// their rate, then recover gradually on successful requests.
+type RateLimiter struct {
+	mu     sync.Mutex
+	global *tokenBucket
+	paths  []pathBucket
+}
+
+type pathBucket struct {
+	prefix string
+	bucket *tokenBucket
+}
+
+type tokenBucket struct {
+	base   float64
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+const (
+	throttleFactor = 0.5
+	recoveryStep   = 0.05
+	minRateFactor  = 0.05
+)
+
+// NewRateLimiter allows rps requests per second with the given burst. A
+// non-positive rps disables the global limit.
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	l := &RateLimiter{}
+	if rps > 0 {
+		l.global = newTokenBucket(rps, burst)
+	}
+	return l
+}
+
+func newTokenBucket(rps float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{base: rps, rate: rps, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+func (l *RateLimiter) setGlobal(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.global = nil
+	if rps > 0 {
+		l.global = newTokenBucket(rps, burst)
+	}
+}
+
+// SetPathLimit limits requests whose path starts with prefix (e.g. "/tx/").
+func (l *RateLimiter) SetPathLimit(prefix string, rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, p := range l.paths {
+		if p.prefix == prefix {
+			l.paths[i].bucket = newTokenBucket(rps, burst)
+			return
+		}
+	}
+	l.paths = append(l.paths, pathBucket{prefix: prefix, bucket: newTokenBucket(rps, burst)})
+}
+
+// Rate returns the current effective global rate in requests per second.
+func (l *RateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.global == nil {
+		return math.Inf(1)
+	}
+	return l.global.rate
+}
+
+func (l *RateLimiter) buckets(path string) []*tokenBucket {
+	var bs []*tokenBucket
+	if l.global != nil {
+		bs = append(bs, l.global)
+	}
+	for _, p := range l.paths {
+		if strings.HasPrefix(path, p.prefix) {
+			bs = append(bs, p.bucket)
+		}
+	}
+	return bs
+}
+
+// Wait blocks until a request to path is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, path string) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		var delay time.Duration
+		bs := l.buckets(path)
+		for _, b := range bs {
+			b.refill(now)
+			if b.tokens < 1 {
+				if d := time.Duration((1 - b.tokens) / b.rate * float64(time.Second)); d > delay {
+					delay = d
+				}
+			}
+		}
+		if delay == 0 {
+			for _, b := range bs {
+				b.tokens--
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Throttle lowers the rate of every bucket that applies to path.
+func (l *RateLimiter) Throttle(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, b := range l.buckets(path) {
+		b.rate = math.Max(b.rate*throttleFactor, b.base*minRateFactor)
+		b.tokens = math.Min(b.tokens, 0)
+	}
+}
+
+// Recover moves the buckets that apply to path back towards their base rate.
+func (l *RateLimiter) Recover(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, b := range l.buckets(path) {
+		if b.rate < b.base {
+			b.rate = math.Min(b.base, b.rate+b.base*recoveryStep)
+		}
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
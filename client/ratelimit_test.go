@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterBlocksAndAdapts(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"nonce":1}`))
+	}))
+	defer srv.Close()
+
+	limiter := NewRateLimiter(50, 1)
+	oc := NewClient(srv.URL, WithRateLimiter(limiter), WithRetryPolicy(fastRetry()))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := oc.GetBalance(context.Background(), "oct1"); err != nil {
+			t.Fatalf("GetBalance failed: %v", err)
+		}
+	}
+	// 5 requests at 50 rps (25 rps right after the 429) need at least ~80ms.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("limiter did not block: 5 requests in %v", elapsed)
+	}
+	if rate := limiter.Rate(); rate >= 50 {
+		t.Errorf("rate should adapt downward after 429, got %v", rate)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx, "/balance/oct1"); err == nil {
+		t.Errorf("Wait must honor context cancellation once tokens are exhausted")
+	}
+}
@@ -0,0 +1,245 @@
+// client/receipt.go
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// SubmitResult is the node's answer to POST /send-tx.
+type SubmitResult struct {
+	Status  string `json:"status"`
+	TxHash  string `json:"tx_hash"`
+	OUCost  string `json:"ou_cost,omitempty"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (r *SubmitResult) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		Status  flexString `json:"status"`
+		TxHash  flexString `json:"tx_hash"`
+		Hash    flexString `json:"hash"`
+		OUCost  flexString `json:"ou_cost"`
+		Message flexString `json:"message"`
+		Error   flexString `json:"error"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*r = SubmitResult{
+		Status:  string(aux.Status),
+		TxHash:  firstNonEmpty(string(aux.TxHash), string(aux.Hash)),
+		OUCost:  string(aux.OUCost),
+		Message: string(aux.Message),
+		Error:   string(aux.Error),
+	}
+	return nil
+}
+
+// ParsedTransaction is the decoded transaction body the node returns in
+// "parsed_tx". Amount is always a plain OCT decimal without unit suffix.
+type ParsedTransaction struct {
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	Amount    string      `json:"amount"`
+	AmountRaw string      `json:"amount_raw,omitempty"`
+	Nonce     uint64      `json:"nonce"`
+	OU        string      `json:"ou"`
+	Timestamp json.Number `json:"timestamp"`
+	Message   string      `json:"message,omitempty"`
+}
+
+func (p *ParsedTransaction) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		From      flexString `json:"from"`
+		To        flexString `json:"to"`
+		ToAlt     flexString `json:"to_"`
+		Amount    flexString `json:"amount"`
+		AmountRaw flexString `json:"amount_raw"`
+		Nonce     flexUint   `json:"nonce"`
+		OU        flexString `json:"ou"`
+		Timestamp flexString `json:"timestamp"`
+		Message   flexString `json:"message"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*p = ParsedTransaction{
+		From:      string(aux.From),
+		To:        firstNonEmpty(string(aux.To), string(aux.ToAlt)),
+		Amount:    normalizeAmount(string(aux.Amount)),
+		AmountRaw: string(aux.AmountRaw),
+		Nonce:     uint64(aux.Nonce),
+		OU:        string(aux.OU),
+		Timestamp: json.Number(aux.Timestamp),
+		Message:   string(aux.Message),
+	}
+	return nil
+}
+
+// AmountAtoms returns the transferred amount in atoms, preferring the exact
+// amount_raw value when the node supplies it.
+func (p *ParsedTransaction) AmountAtoms() (*big.Int, error) {
+	if p.AmountRaw != "" {
+		atoms, ok := new(big.Int).SetString(p.AmountRaw, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount_raw %q", p.AmountRaw)
+		}
+		return atoms, nil
+	}
+	return decimalToAtoms(p.Amount)
+}
+
+// TransactionDetail is the node's answer to GET /tx/{hash}.
+type TransactionDetail struct {
+	Hash      string            `json:"tx_hash"`
+	Status    string            `json:"status"`
+	Epoch     uint64            `json:"epoch,omitempty"`
+	Parsed    ParsedTransaction `json:"parsed_tx"`
+	OU        string            `json:"ou,omitempty"`
+	Message   string            `json:"message,omitempty"`
+	Signature string            `json:"signature,omitempty"`
+	PublicKey string            `json:"public_key,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func (d *TransactionDetail) UnmarshalJSON(b []byte) error {
+	var aux struct {
+		TxHash    flexString         `json:"tx_hash"`
+		Hash      flexString         `json:"hash"`
+		Status    flexString         `json:"status"`
+		Epoch     flexUint           `json:"epoch"`
+		Parsed    *ParsedTransaction `json:"parsed_tx"`
+		OU        flexString         `json:"ou"`
+		Message   flexString         `json:"message"`
+		Signature flexString         `json:"signature"`
+		PublicKey flexString         `json:"public_key"`
+		Error     flexString         `json:"error"`
+		Reason    flexString         `json:"reason"`
+		Data      json.RawMessage    `json:"data"`
+	}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	*d = TransactionDetail{
+		Hash:      firstNonEmpty(string(aux.TxHash), string(aux.Hash)),
+		Status:    string(aux.Status),
+		Epoch:     uint64(aux.Epoch),
+		OU:        string(aux.OU),
+		Message:   string(aux.Message),
+		Signature: string(aux.Signature),
+		PublicKey: string(aux.PublicKey),
+		Error:     firstNonEmpty(string(aux.Error), string(aux.Reason)),
+	}
+	if aux.Parsed != nil {
+		d.Parsed = *aux.Parsed
+	} else if err := d.Parsed.UnmarshalJSON(b); err != nil {
+		// Epoch listings inline the transaction body next to the hash.
+		return err
+	}
+
+	// Some node builds only carry the signed envelope inside "data", either
+	// as an object or as a JSON-encoded string.
+	if len(aux.Data) > 0 && (d.Signature == "" || d.PublicKey == "") {
+		raw := []byte(aux.Data)
+		var inner string
+		if json.Unmarshal(raw, &inner) == nil {
+			raw = []byte(inner)
+		}
+		var env struct {
+			Signature flexString `json:"signature"`
+			PublicKey flexString `json:"public_key"`
+			Message   flexString `json:"message"`
+		}
+		if json.Unmarshal(raw, &env) == nil {
+			d.Signature = firstNonEmpty(d.Signature, string(env.Signature))
+			d.PublicKey = firstNonEmpty(d.PublicKey, string(env.PublicKey))
+			d.Message = firstNonEmpty(d.Message, string(env.Message))
+		}
+	}
+
+	d.OU = firstNonEmpty(d.OU, d.Parsed.OU)
+	d.Message = firstNonEmpty(d.Message, d.Parsed.Message)
+	return nil
+}
+
+// Confirmed reports whether the transaction has been included in an epoch.
+func (d *TransactionDetail) Confirmed() bool {
+	return d.Status == "confirmed" || d.Epoch > 0
+}
+
+// flexString accepts a JSON string or number and keeps its textual form.
+type flexString string
+
+func (f *flexString) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*f = flexString(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*f = flexString(n.String())
+	return nil
+}
+
+// flexUint accepts a JSON number or a numeric string.
+type flexUint uint64
+
+func (f *flexUint) UnmarshalJSON(b []byte) error {
+	var s flexString
+	if err := s.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	v, err := strconv.ParseUint(string(s), 10, 64)
+	if err != nil {
+		fv, ferr := strconv.ParseFloat(string(s), 64)
+		if ferr != nil || fv < 0 {
+			return fmt.Errorf("invalid unsigned value %q", string(s))
+		}
+		v = uint64(fv)
+	}
+	*f = flexUint(v)
+	return nil
+}
+
+func normalizeAmount(s string) string {
+	s = strings.TrimSpace(s)
+	if fields := strings.Fields(s); len(fields) == 2 && strings.EqualFold(fields[1], "OCT") {
+		s = fields[0]
+	}
+	return s
+}
+
+func decimalToAtoms(amount string) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(normalizeAmount(amount))
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	r.Mul(r, big.NewRat(1e6, 1))
+	return new(big.Int).Quo(r.Num(), r.Denom()), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
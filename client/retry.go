@@ -0,0 +1,183 @@
+// client/retry.go
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how OctraClient repeats requests that failed for a
+// transient reason (connection errors, 429 and 5xx answers). GET requests are
+// retried automatically; POST /send-tx is only repeated once the client has
+// proven the earlier submission did not land.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   250 * time.Millisecond,
+		MaxDelay:    8 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Backoff returns the delay before the given retry (1 = first retry). A
+// Retry-After hint from the node takes precedence when it is longer.
+func (p RetryPolicy) Backoff(retry int, err error) time.Duration {
+	// A zero MaxDelay leaves the delay uncapped; it saturates instead of
+	// overflowing.
+	delay := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		if delay > math.MaxInt64/2 {
+			delay = math.MaxInt64
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 && delay > 0 {
+		spread := float64(delay) * p.Jitter
+		if jitter := time.Duration((rand.Float64()*2 - 1) * spread); jitter > 0 && delay > math.MaxInt64-jitter {
+			delay = math.MaxInt64
+		} else {
+			delay += jitter
+		}
+	}
+
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.RetryAfter > delay {
+		delay = rpcErr.RetryAfter
+	}
+	return delay
+}
+
+// IsRetryable reports whether err is a transient failure worth repeating:
+// a timeout, a reset or refused connection, a truncated response, or a 429
+// or 5xx answer.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Temporary()
+	}
+	// http.Client wraps every transport failure in a *url.Error, which is
+	// itself a net.Error; judge the underlying failure instead, so TLS,
+	// pinning and malformed-URL errors fail at once.
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+func parseRetryAfter(header http.Header) time.Duration {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+type attemptKey struct{}
+
+// priorAttempts returns how many attempts of the same request were already
+// made by an outer retry loop such as sendWithRetry.
+func priorAttempts(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok && n > 1 {
+		return n - 1
+	}
+	return 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// sendWithRetry submits signedTx and, after a transient failure, looks the
+// transaction hash up before resubmitting so a submission that already
+// reached the node is never broadcast twice.
+func (c *OctraClient) sendWithRetry(ctx context.Context, signedTx *SignedTransaction) (*SubmitResult, error) {
+	hash := signedTx.Hash()
+	landed := &SubmitResult{Status: "accepted", TxHash: hash}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.submit(context.WithValue(ctx, attemptKey{}, attempt), signedTx)
+		if err == nil {
+			return res, nil
+		}
+		if attempt > 1 && (errors.Is(err, ErrDuplicateTx) || errors.Is(err, ErrNonceTooLow)) {
+			if errors.Is(err, ErrDuplicateTx) {
+				return landed, nil
+			}
+			if _, lerr := c.GetTransaction(ctx, hash); lerr == nil {
+				return landed, nil
+			}
+			return nil, err
+		}
+		if !IsRetryable(err) || attempt >= c.retry.attempts() || hash == "" {
+			return nil, err
+		}
+
+		if _, lerr := c.GetTransaction(ctx, hash); lerr == nil {
+			return landed, nil
+		} else if !errors.Is(lerr, ErrNotFound) {
+			return nil, err
+		}
+		if serr := sleepContext(ctx, c.retry.Backoff(attempt, err)); serr != nil {
+			return nil, err
+		}
+	}
+}
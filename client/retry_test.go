@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func fastRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+func TestRetryIdempotentGet(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"address":"oct1","balance":"1.5","nonce":3}`))
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL, WithRetryPolicy(fastRetry()))
+	info, err := oc.GetBalance(context.Background(), "oct1")
+	if err != nil || info.Nonce != 3 {
+		t.Fatalf("expected balance after retries, got %+v (%v)", info, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestRetrySendTxDetectsLandedSubmission(t *testing.T) {
+	_, _, priv, _ := GenerateNewKeyPair()
+	signed, _ := SignTransaction(Transaction{
+		From: "octA", To: "octB", Amount: "1000", Nonce: 1, Timestamp: json.Number("1737273600"),
+	}, priv)
+
+	var sends int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/send-tx":
+			atomic.AddInt32(&sends, 1)
+			// The node stored the tx but the gateway failed the response.
+			w.WriteHeader(http.StatusBadGateway)
+		case "/tx/" + signed.Hash():
+			w.Write([]byte(`{"status":"pending"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL, WithRetryPolicy(fastRetry()))
+	res, err := oc.SendTransaction(context.Background(), signed)
+	if err != nil || res.TxHash != signed.Hash() {
+		t.Fatalf("expected landed submission to be reported, got %+v (%v)", res, err)
+	}
+	if sends != 1 {
+		t.Errorf("transaction must not be resubmitted once it landed, sent %d times", sends)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cases := []struct {
+		policy RetryPolicy
+		retry  int
+		want   time.Duration
+	}{
+		{RetryPolicy{BaseDelay: 100 * time.Millisecond}, 1, 100 * time.Millisecond},
+		{RetryPolicy{BaseDelay: 100 * time.Millisecond}, 4, 800 * time.Millisecond},
+		{RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}, 4, 300 * time.Millisecond},
+		{RetryPolicy{BaseDelay: time.Second}, 100, math.MaxInt64},
+	}
+	for _, tc := range cases {
+		if got := tc.policy.Backoff(tc.retry, nil); got != tc.want {
+			t.Errorf("%+v retry %d: expected %v, got %v", tc.policy, tc.retry, tc.want, got)
+		}
+	}
+	if got := (RetryPolicy{BaseDelay: time.Second, Jitter: 0.5}).Backoff(100, nil); got <= 0 {
+		t.Errorf("jitter overflowed an uncapped delay: %v", got)
+	}
+}
+
+type failingTransport struct {
+	err   error
+	calls int32
+}
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	atomic.AddInt32(&f.calls, 1)
+	return nil, f.err
+}
+
+func TestRetryOnlyTransientTransportErrors(t *testing.T) {
+	cases := []struct {
+		err   error
+		calls int32
+	}{
+		{ErrCertificatePin, 1},
+		{x509.UnknownAuthorityError{}, 1},
+		{&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, 4},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, 4},
+	}
+	for _, tc := range cases {
+		ft := &failingTransport{err: tc.err}
+		oc := NewClient("http://node.invalid", WithTransport(ft), WithRetryPolicy(fastRetry()))
+		if _, err := oc.GetBalance(context.Background(), "oct1"); !errors.Is(err, tc.err) {
+			t.Errorf("%v: expected the transport error, got %v", tc.err, err)
+		}
+		if ft.calls != tc.calls {
+			t.Errorf("%v: expected %d attempts, got %d", tc.err, tc.calls, ft.calls)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// client/subscribe.go
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often SubscribeEpochs polls nodes without an
+// epoch stream.
+const DefaultPollInterval = 2 * time.Second
+
+// EpochSubscription delivers epochs in order, see SubscribeEpochs.
+type EpochSubscription struct {
+	epochs chan *Epoch
+	err    error
+}
+
+// Epochs returns the channel epochs are delivered on. It is closed when the
+// subscription ends.
+func (s *EpochSubscription) Epochs() <-chan *Epoch { return s.epochs }
+
+// Err returns why the subscription ended: ctx's error, or the error of an
+// epoch that could not be fetched. It is only valid once Epochs is closed.
+func (s *EpochSubscription) Err() error { return s.err }
+
+// SubscribeEpochs delivers every epoch from fromEpoch on, with its
+// transactions, in order and without gaps. A fromEpoch of 0 starts with the
+// next epoch produced. Nodes advertising an epoch stream push new epoch
+// numbers over server-sent events; otherwise the node is polled every
+// WithPollInterval. Either way missing epochs are back-filled with GetEpoch,
+// so a dropped stream never loses an epoch.
+func (c *OctraClient) SubscribeEpochs(ctx context.Context, fromEpoch uint64) *EpochSubscription {
+	sub := &EpochSubscription{epochs: make(chan *Epoch)}
+	go func() {
+		defer close(sub.epochs)
+		_, sub.err = traced(ctx, c, "SubscribeEpochs", func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, c.runEpochSubscription(withOperation(ctx, "SubscribeEpochs"), fromEpoch, sub.epochs)
+		})
+	}()
+	return sub
+}
+
+func (c *OctraClient) runEpochSubscription(ctx context.Context, next uint64, out chan<- *Epoch) error {
+	if next == 0 {
+		tip, err := c.CurrentEpoch(ctx)
+		if err != nil {
+			return err
+		}
+		next = tip + 1
+	}
+	// catchUp delivers next..tip and stops at the first failure. An
+	// announced epoch the node cannot serve yet (e.g. a lagging replica) is
+	// retried with the next announcement or poll.
+	catchUp := func(tip uint64) error {
+		for ; next <= tip; next++ {
+			epoch, err := c.GetEpoch(ctx, next)
+			if errors.Is(err, ErrNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			select {
+			case out <- epoch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+
+	for {
+		info, err := c.NodeInfo(ctx)
+		if err == nil && info.Features[FeatureEpochStream] {
+			err = c.streamEpochs(ctx, next, catchUp)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !IsRetryable(err) {
+			return err
+		}
+		// Poll once between stream attempts, or on every tick when the node
+		// has no stream.
+		tip, err := c.CurrentEpoch(ctx)
+		if err == nil {
+			err = catchUp(tip)
+		}
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !IsRetryable(err):
+			return err
+		}
+		if err := sleepContext(ctx, c.pollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// streamEpochs follows GET /epochs/stream until it fails, calling catchUp
+// with every announced epoch number.
+func (c *OctraClient) streamEpochs(ctx context.Context, from uint64, catchUp func(tip uint64) error) error {
+	resp, err := c.openStream(ctx, fmt.Sprintf("/epochs/stream?from=%d", from))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			continue
+		}
+		if line != "" || data.Len() == 0 {
+			continue
+		}
+		var event struct {
+			Epoch flexUint `json:"epoch"`
+		}
+		err := json.Unmarshal([]byte(data.String()), &event)
+		data.Reset()
+		if err != nil {
+			continue
+		}
+		if err := catchUp(uint64(event.Epoch)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// The node closed the stream; treated as transient.
+	return io.ErrUnexpectedEOF
+}
+
+// openStream opens a server-sent event stream at path. Opening it is an RPC
+// like any other: it passes the interceptors and the rate limiter, and its
+// outcome is reported to the endpoint pool. The body is the caller's to
+// close.
+func (c *OctraClient) openStream(ctx context.Context, path string) (*http.Response, error) {
+	var resp *http.Response
+	call := &Call{Parent: methodCallFrom(ctx), Operation: operationFrom(ctx, "SubscribeEpochs"), Method: "GET", Path: path}
+	err := c.chain(ctx, call, func(ctx context.Context, call *Call) error {
+		call.Attempts = 1
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx, path); err != nil {
+				call.Err = err
+				return err
+			}
+		}
+		if c.endpoints == nil {
+			resp, call.Err = c.connectStream(ctx, c.baseURL, path, call.Header)
+		} else {
+			node := c.endpoints.pick()
+			if node == nil {
+				call.Err = ErrNoEndpoints
+				return call.Err
+			}
+			start := time.Now()
+			resp, call.Err = c.connectStream(ctx, node.url, path, call.Header)
+			c.endpoints.report(node, time.Since(start), call.Err)
+		}
+		if c.limiter != nil {
+			if errors.Is(call.Err, ErrRateLimited) {
+				c.limiter.Throttle(path)
+			} else if call.Err == nil {
+				c.limiter.Recover(path)
+			}
+		}
+		return call.Err
+	})
+	return resp, err
+}
+
+func (c *OctraClient) connectStream(ctx context.Context, baseURL, path string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// The stream outlives the client's per-request timeout.
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		rpcErr := newRPCError(resp.StatusCode, "GET", path, data)
+		rpcErr.RetryAfter = parseRetryAfter(resp.Header)
+		return nil, rpcErr
+	}
+	return resp, nil
+}
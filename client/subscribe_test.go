@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSubscribeRetriesAnnouncedEpochNotFound(t *testing.T) {
+	var lookups int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			w.Write([]byte(`{"version":"test","epoch":1,"features":["status","epochs"]}`))
+		case "/epoch/1":
+			// The node announces epoch 1 before it can serve it.
+			if atomic.AddInt32(&lookups, 1) == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"epoch not found"}`))
+				return
+			}
+			w.Write([]byte(`{"epoch":1,"transactions":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	oc := NewClient(srv.URL, WithPollInterval(5*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub := oc.SubscribeEpochs(ctx, 1)
+	if epoch := <-sub.Epochs(); epoch == nil || epoch.Number != 1 {
+		t.Fatalf("expected epoch 1 once the node serves it, got %+v (err %v)", epoch, sub.Err())
+	}
+	if n := atomic.LoadInt32(&lookups); n != 2 {
+		t.Errorf("expected the missing epoch to be looked up again, got %d lookups", n)
+	}
+}
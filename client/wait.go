@@ -0,0 +1,495 @@
+// client/wait.go
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// TxStatus is the lifecycle stage of a submitted transaction as observed by
+// WaitForTransaction.
+type TxStatus string
+
+const (
+	// TxPending means the node does not know the transaction yet.
+	TxPending TxStatus = "pending"
+	// TxStaged means the transaction is accepted and waits for an epoch.
+	TxStaged    TxStatus = "staged"
+	TxConfirmed TxStatus = "confirmed"
+	TxRejected  TxStatus = "rejected"
+	TxDropped   TxStatus = "dropped"
+)
+
+var (
+	ErrTxRejected  = errors.New("octra: transaction rejected")
+	ErrTxDropped   = errors.New("octra: transaction dropped")
+	ErrWaitTimeout = errors.New("octra: wait timed out")
+	// ErrNoEpoch is returned when waiting for a confirmation depth but the
+	// node keeps reporting the transaction confirmed without its epoch.
+	ErrNoEpoch = errors.New("octra: confirmed transaction has no epoch")
+)
+
+// TxError reports a transaction that will never confirm. It matches
+// ErrTxRejected or ErrTxDropped with errors.Is.
+type TxError struct {
+	Hash   string
+	Status TxStatus
+	Reason string
+}
+
+func (e *TxError) Error() string {
+	msg := fmt.Sprintf("octra: transaction %s %s", e.Hash, e.Status)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *TxError) Is(target error) bool {
+	switch target {
+	case ErrTxRejected:
+		return e.Status == TxRejected
+	case ErrTxDropped:
+		return e.Status == TxDropped
+	}
+	return false
+}
+
+// WaitOptions controls WaitForTransaction. The zero value polls after 1s,
+// backing off by 1.5x up to 10s, until ctx is done.
+type WaitOptions struct {
+	// Timeout bounds the whole wait; zero relies on ctx alone.
+	Timeout time.Duration
+	// Interval is the delay between the first polls.
+	Interval time.Duration
+	// MaxInterval caps the delay once backoff kicks in.
+	MaxInterval time.Duration
+	// Multiplier grows the delay after every poll without a status change.
+	Multiplier float64
+	// DropAfter is the number of consecutive "not found" answers after the
+	// transaction was staged before it is reported as dropped, and of
+	// "confirmed" answers without an epoch before waiting for Depth fails
+	// with ErrNoEpoch (default 3).
+	DropAfter int
+	// OnStatus is called on every status transition, starting with the
+	// first observed status.
+	OnStatus func(hash string, status TxStatus, tx *TransactionDetail)
+	// Depth is the number of epochs, counting the including one, that must
+	// exist before a confirmed transaction resolves. Zero uses the client's
+	// WithConfirmationDepth setting; 1 resolves on inclusion.
+	Depth uint64
+	// OnConfirmations is called whenever the confirmation count of a
+	// confirmed transaction grows while waiting for Depth.
+	OnConfirmations func(hash string, confirmations uint64)
+}
+
+func (o WaitOptions) withDefaults(c *OctraClient) WaitOptions {
+	if o.Depth == 0 {
+		o.Depth = c.depth
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.MaxInterval < o.Interval {
+		o.MaxInterval = max(10*time.Second, o.Interval)
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	if o.DropAfter <= 0 {
+		o.DropAfter = 3
+	}
+	return o
+}
+
+// Receipt describes a confirmed transaction.
+type Receipt struct {
+	Hash  string
+	Epoch uint64
+	// Confirmations is the number of epochs since inclusion, counting the
+	// including one, when the wait resolved.
+	Confirmations uint64
+	From          string
+	To            string
+	Amount        *big.Int
+	OU            string
+	Message       string
+	// Detail is the node's full answer for the transaction.
+	Detail *TransactionDetail
+}
+
+// newReceipt builds a Receipt from a confirmed transaction. Amount is nil
+// when the node's amount cannot be parsed.
+func newReceipt(tx *TransactionDetail) *Receipt {
+	amount, _ := tx.Parsed.AmountAtoms()
+	return &Receipt{
+		Hash:    tx.Hash,
+		Epoch:   tx.Epoch,
+		From:    tx.Parsed.From,
+		To:      tx.Parsed.To,
+		Amount:  amount,
+		OU:      tx.OU,
+		Message: tx.Message,
+		Detail:  tx,
+	}
+}
+
+// statusOf maps the node's answer to a TxStatus.
+func statusOf(tx *TransactionDetail) TxStatus {
+	if tx.Confirmed() {
+		return TxConfirmed
+	}
+	switch strings.ToLower(tx.Status) {
+	case "rejected", "failed", "invalid":
+		return TxRejected
+	case "dropped", "expired", "evicted":
+		return TxDropped
+	}
+	return TxStaged
+}
+
+// txTracker follows one hash through its lifecycle.
+type txTracker struct {
+	hash    string
+	last    TxStatus
+	tx      *TransactionDetail
+	confs   uint64
+	seen    bool
+	missing int
+	noEpoch int
+}
+
+// observe folds a lookup result into the tracker and returns the current
+// status. Errors other than ErrNotFound are returned unchanged.
+func (t *txTracker) observe(tx *TransactionDetail, err error, opts WaitOptions) (TxStatus, error) {
+	switch {
+	case err == nil:
+		status := statusOf(tx)
+		t.missing = 0
+		if status == TxConfirmed && tx.Epoch == 0 {
+			t.noEpoch++
+		} else {
+			t.noEpoch = 0
+		}
+		t.seen = t.seen || status == TxStaged
+		return status, nil
+	case errors.Is(err, ErrNotFound):
+		t.missing++
+		if !t.seen {
+			return TxPending, nil
+		}
+		if t.missing >= opts.DropAfter {
+			return TxDropped, nil
+		}
+		return t.last, nil
+	}
+	return t.last, err
+}
+
+// transition records status and reports whether it changed.
+func (t *txTracker) transition(status TxStatus, tx *TransactionDetail, opts WaitOptions) bool {
+	if tx != nil {
+		t.tx = tx
+	}
+	if status == t.last {
+		return false
+	}
+	t.last = status
+	if opts.OnStatus != nil {
+		opts.OnStatus(t.hash, status, tx)
+	}
+	return true
+}
+
+// needsLookup reports whether the transaction itself must be fetched again:
+// until it is confirmed, and while its epoch is unknown but needed for
+// Depth.
+func (t *txTracker) needsLookup(opts WaitOptions) bool {
+	return t.last != TxConfirmed || opts.Depth > 1 && t.tx.Epoch == 0
+}
+
+// needsTip reports whether result needs the current epoch.
+func (t *txTracker) needsTip(opts WaitOptions) bool {
+	return t.last == TxConfirmed && opts.Depth > 1
+}
+
+// result returns the final WaitResult once the tracker reached a terminal
+// status and depth, and nil otherwise. tip is the current epoch and only
+// matters when needsTip.
+func (t *txTracker) result(tip uint64, opts WaitOptions) *WaitResult {
+	switch t.last {
+	case TxConfirmed:
+		if opts.Depth > 1 && t.tx.Epoch == 0 {
+			if t.noEpoch >= opts.DropAfter {
+				return &WaitResult{Hash: t.hash, Err: fmt.Errorf("%w: %s, cannot wait for %d confirmations", ErrNoEpoch, t.hash, opts.Depth)}
+			}
+			return nil
+		}
+		// A node may report "confirmed" without the epoch; count it once.
+		confs := max(Confirmations(t.tx.Epoch, max(tip, t.tx.Epoch)), 1)
+		if confs > t.confs {
+			t.confs = confs
+			if opts.OnConfirmations != nil && opts.Depth > 1 {
+				opts.OnConfirmations(t.hash, confs)
+			}
+		}
+		if confs < opts.Depth {
+			return nil
+		}
+		receipt := newReceipt(t.tx)
+		receipt.Confirmations = confs
+		return &WaitResult{Hash: t.hash, Receipt: receipt}
+	case TxRejected, TxDropped:
+		txErr := &TxError{Hash: t.hash, Status: t.last}
+		if t.tx != nil && t.last == TxRejected {
+			txErr.Reason = t.tx.Error
+		}
+		return &WaitResult{Hash: t.hash, Err: txErr}
+	}
+	return nil
+}
+
+func (c *OctraClient) waitContext(ctx context.Context, opts WaitOptions) (context.Context, context.CancelFunc) {
+	ctx, cancel := c.withDeadline(ctx, "WaitTransaction")
+	if opts.Timeout <= 0 {
+		return withOperation(ctx, "WaitTransaction"), cancel
+	}
+	ctx, cancelTimeout := context.WithTimeoutCause(ctx, opts.Timeout, fmt.Errorf("%w after %s", ErrWaitTimeout, opts.Timeout))
+	return withOperation(ctx, "WaitTransaction"), func() { cancelTimeout(); cancel() }
+}
+
+// WaitForTransaction polls until hash is confirmed and returns its receipt.
+// It returns a *TxError early when the node rejects the transaction or it
+// disappears after having been staged, an error matching ErrWaitTimeout
+// when opts.Timeout elapses, and any non-transient lookup error as is.
+func (c *OctraClient) WaitForTransaction(ctx context.Context, hash string, opts WaitOptions) (*Receipt, error) {
+	return traced(ctx, c, "WaitForTransaction", func(ctx context.Context) (*Receipt, error) {
+		opts = opts.withDefaults(c)
+		ctx, cancel := c.waitContext(ctx, opts)
+		defer cancel()
+
+		t := &txTracker{hash: hash}
+		delay := opts.Interval
+		for {
+			var tip uint64
+			var err error
+			progressed := false
+			if t.needsLookup(opts) {
+				var tx *TransactionDetail
+				var status TxStatus
+				tx, err = c.GetTransaction(ctx, hash)
+				status, err = t.observe(tx, err, opts)
+				progressed = t.transition(status, tx, opts)
+			}
+			if err == nil && t.needsTip(opts) {
+				// Inclusion is final; only the tip still moves.
+				tip, err = c.CurrentEpoch(ctx)
+			}
+			switch {
+			case err == nil:
+			case ctx.Err() != nil:
+				return nil, context.Cause(ctx)
+			case !IsRetryable(err):
+				return nil, err
+			}
+			confs := t.confs
+			if res := t.result(tip, opts); res != nil {
+				return res.Receipt, res.Err
+			}
+			if progressed || t.confs > confs {
+				delay = opts.Interval
+			} else {
+				delay = min(time.Duration(float64(delay)*opts.Multiplier), opts.MaxInterval)
+			}
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, context.Cause(ctx)
+			}
+		}
+	})
+}
+
+// WaitResult is the outcome of one hash in WaitTransactions.
+type WaitResult struct {
+	Hash    string
+	Receipt *Receipt
+	Err     error
+}
+
+// WaitTransactions waits for many hashes with one shared polling loop and
+// sends each hash's result on the returned channel as soon as it is final.
+// The channel is closed once every hash has a result; hashes still open when
+// ctx is done or opts.Timeout elapses get the context's cause as Err.
+//
+// On nodes with epoch and staging endpoints each round reads the new epochs
+// and the staging list, and only looks up hashes missing from both. Other
+// nodes are polled per hash. Lookups run on at most WithConcurrency
+// workers.
+func (c *OctraClient) WaitTransactions(ctx context.Context, hashes []string, opts WaitOptions) <-chan WaitResult {
+	opts = opts.withDefaults(c)
+	out := make(chan WaitResult, len(hashes))
+	open := make(map[string]*txTracker, len(hashes))
+	for _, h := range hashes {
+		open[h] = &txTracker{hash: h}
+	}
+	go func() {
+		defer close(out)
+		traced(ctx, c, "WaitTransactions", func(ctx context.Context) (struct{}, error) {
+			ctx, cancel := c.waitContext(ctx, opts)
+			defer cancel()
+
+			w := &multiWait{c: c, opts: opts, open: open, out: out}
+			delay := opts.Interval
+			for len(open) > 0 {
+				if w.round(ctx) {
+					delay = opts.Interval
+				} else {
+					delay = min(time.Duration(float64(delay)*opts.Multiplier), opts.MaxInterval)
+				}
+				if len(open) == 0 {
+					return struct{}{}, nil
+				}
+				if err := sleepContext(ctx, delay); err != nil {
+					for h := range open {
+						out <- WaitResult{Hash: h, Err: context.Cause(ctx)}
+					}
+					return struct{}{}, context.Cause(ctx)
+				}
+			}
+			return struct{}{}, nil
+		})
+	}()
+	return out
+}
+
+type multiWait struct {
+	c     *OctraClient
+	opts  WaitOptions
+	open  map[string]*txTracker
+	out   chan<- WaitResult
+	epoch uint64 // last epoch scanned, 0 before the first round
+
+	// tip is the current epoch, fetched at most once per round.
+	tip    uint64
+	tipErr error
+	hasTip bool
+}
+
+// round polls once and reports whether any hash made progress.
+func (w *multiWait) round(ctx context.Context) bool {
+	changed := false
+	w.hasTip = false
+	update := func(t *txTracker, status TxStatus, tx *TransactionDetail) {
+		if t.transition(status, tx, w.opts) {
+			changed = true
+		}
+		if t.needsTip(w.opts) && !w.hasTip {
+			w.tip, w.tipErr = w.c.CurrentEpoch(ctx)
+			w.hasTip = true
+		}
+		if t.needsTip(w.opts) && w.tipErr != nil && ctx.Err() == nil && !IsRetryable(w.tipErr) {
+			w.settle(WaitResult{Hash: t.hash, Err: w.tipErr})
+			return
+		}
+		confs := t.confs
+		if res := t.result(w.tip, w.opts); res != nil {
+			w.settle(*res)
+		}
+		changed = changed || t.confs > confs
+	}
+
+	var lookup []*txTracker
+	for _, t := range w.scan(ctx, update) {
+		if w.open[t.hash] != nil && t.needsLookup(w.opts) {
+			lookup = append(lookup, t)
+		}
+	}
+	details := make([]*TransactionDetail, len(lookup))
+	errs := make([]error, len(lookup))
+	w.c.forEach(ctx, len(lookup), func(ctx context.Context, i int) {
+		details[i], errs[i] = w.c.GetTransaction(ctx, lookup[i].hash)
+	})
+	if ctx.Err() != nil {
+		return changed
+	}
+	for i, t := range lookup {
+		status, err := t.observe(details[i], errs[i], w.opts)
+		if err != nil && !IsRetryable(err) {
+			w.settle(WaitResult{Hash: t.hash, Err: err})
+			continue
+		}
+		update(t, status, details[i])
+	}
+	// Confirmed hashes only wait for the tip to reach their depth.
+	for _, t := range w.open {
+		if t.last == TxConfirmed {
+			update(t, TxConfirmed, nil)
+		}
+	}
+	return changed
+}
+
+func (w *multiWait) settle(res WaitResult) {
+	delete(w.open, res.Hash)
+	w.out <- res
+}
+
+// scan settles what the epoch and staging endpoints can tell about the open
+// hashes and returns the trackers that still need a per-hash lookup. The
+// result may include trackers settled during the scan.
+func (w *multiWait) scan(ctx context.Context, update func(*txTracker, TxStatus, *TransactionDetail)) []*txTracker {
+	all := make([]*txTracker, 0, len(w.open))
+	for _, t := range w.open {
+		if t.needsLookup(w.opts) {
+			all = append(all, t)
+		}
+	}
+	info, err := w.c.NodeInfo(ctx)
+	if err != nil || !info.Features[FeatureEpochs] || !info.Features[FeatureStaging] || !info.Features[FeatureStatus] {
+		return all
+	}
+	status, err := w.c.GetStatus(ctx)
+	if err != nil {
+		return all
+	}
+	w.tip, w.tipErr, w.hasTip = status.Epoch, nil, true
+	if w.epoch == 0 {
+		// Hashes may have confirmed before the wait started; the first
+		// round looks every hash up and only later rounds scan epochs.
+		w.epoch = status.Epoch
+		return all
+	}
+	for ; w.epoch < status.Epoch; w.epoch++ {
+		epoch, err := w.c.GetEpoch(ctx, w.epoch+1)
+		if err != nil {
+			return all
+		}
+		for i := range epoch.Transactions {
+			tx := &epoch.Transactions[i]
+			if t, ok := w.open[tx.Hash]; ok {
+				update(t, statusOf(tx), tx)
+			}
+		}
+	}
+	staged, err := w.c.GetStagedTransactions(ctx, "")
+	if err != nil {
+		return all
+	}
+	inStaging := make(map[string]StagedTransaction, len(staged))
+	for _, tx := range staged {
+		inStaging[tx.Hash] = tx
+	}
+	var lookup []*txTracker
+	for _, t := range all {
+		if tx, ok := inStaging[t.hash]; ok {
+			t.seen, t.missing = true, 0
+			update(t, TxStaged, &TransactionDetail{Hash: tx.Hash, Status: string(TxStaged), Parsed: tx.ParsedTransaction})
+			continue
+		}
+		lookup = append(lookup, t)
+	}
+	return lookup
+}
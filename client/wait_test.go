@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedTx serves the given /tx answers in order, repeating the last one.
+func scriptedTx(t *testing.T, answers ...string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	i := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := answers[min(i, len(answers)-1)]
+		i++
+		mu.Unlock()
+		if body == "" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"tx not found"}`))
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+var fastWait = WaitOptions{Interval: 5 * time.Millisecond, MaxInterval: 20 * time.Millisecond, Timeout: 2 * time.Second}
+
+func TestWaitForTransactionStatusTransitions(t *testing.T) {
+	srv := scriptedTx(t,
+		"",
+		`{"status":"pending","parsed_tx":{"from":"octA","to":"octB","amount":"1.5"}}`,
+		`{"status":"pending","parsed_tx":{"from":"octA","to":"octB","amount":"1.5"}}`,
+		`{"status":"confirmed","epoch":12,"parsed_tx":{"from":"octA","to":"octB","amount":"1.5","message":"hi"}}`,
+	)
+	var seen []TxStatus
+	opts := fastWait
+	opts.OnStatus = func(hash string, status TxStatus, tx *TransactionDetail) {
+		if hash != "abc" {
+			t.Errorf("callback for wrong hash %q", hash)
+		}
+		seen = append(seen, status)
+	}
+	receipt, err := NewClient(srv.URL).WaitForTransaction(context.Background(), "abc", opts)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if receipt.Epoch != 12 || receipt.Amount.Int64() != 1_500_000 || receipt.Message != "hi" || receipt.Hash != "abc" {
+		t.Errorf("Receipt mismatch: %+v", receipt)
+	}
+	want := []TxStatus{TxPending, TxStaged, TxConfirmed}
+	if len(seen) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("expected transitions %v, got %v", want, seen)
+		}
+	}
+}
+
+func TestWaitForTransactionRejected(t *testing.T) {
+	srv := scriptedTx(t, `{"status":"pending"}`, `{"status":"rejected","reason":"nonce already used"}`)
+	_, err := NewClient(srv.URL).WaitForTransaction(context.Background(), "abc", fastWait)
+	var txErr *TxError
+	if !errors.Is(err, ErrTxRejected) || !errors.As(err, &txErr) || txErr.Reason != "nonce already used" {
+		t.Fatalf("expected ErrTxRejected with reason, got %v", err)
+	}
+}
+
+func TestWaitForTransactionDropped(t *testing.T) {
+	srv := scriptedTx(t, `{"status":"pending"}`, "")
+	_, err := NewClient(srv.URL).WaitForTransaction(context.Background(), "abc", fastWait)
+	if !errors.Is(err, ErrTxDropped) {
+		t.Fatalf("expected ErrTxDropped, got %v", err)
+	}
+}
+
+func TestWaitForTransactionTimeout(t *testing.T) {
+	srv := scriptedTx(t, `{"status":"pending"}`)
+	opts := fastWait
+	opts.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	_, err := NewClient(srv.URL).WaitForTransaction(context.Background(), "abc", opts)
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("expected ErrWaitTimeout, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("timeout took too long: %v", time.Since(start))
+	}
+
+	if _, err := NewClient(srv.URL).WaitTransaction(context.Background(), "abc", 50*time.Millisecond); !errors.Is(err, ErrWaitTimeout) {
+		t.Errorf("expected legacy WaitTransaction to time out, got %v", err)
+	}
+
+	// Without a timeout or ctx deadline the legacy call still gives up.
+	start = time.Now()
+	if _, err := NewClient(srv.URL).WaitTransaction(context.Background(), "abc", 0); !errors.Is(err, ErrWaitTimeout) || time.Since(start) > 3*time.Second {
+		t.Errorf("expected WaitTransaction(0) to give up after one poll interval, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestWaitForDepthWithoutEpoch(t *testing.T) {
+	const noEpoch = `{"status":"confirmed","parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`
+	// node is at epoch 50 and serves the /tx answers in order.
+	node := func(answers ...string) *httptest.Server {
+		var mu sync.Mutex
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/status" {
+				w.Write([]byte(`{"version":"test","epoch":50,"features":["status"]}`))
+				return
+			}
+			mu.Lock()
+			w.Write([]byte(answers[0]))
+			if len(answers) > 1 {
+				answers = answers[1:]
+			}
+			mu.Unlock()
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	opts := fastWait
+	opts.Depth = 3
+
+	// The epoch shows up on a later lookup.
+	srv := node(noEpoch, `{"status":"confirmed","epoch":48,"parsed_tx":{"from":"octA","to":"octB","amount":"1"}}`)
+	receipt, err := NewClient(srv.URL).WaitForTransaction(context.Background(), "abc", opts)
+	if err != nil || receipt.Epoch != 48 || receipt.Confirmations != 3 {
+		t.Fatalf("expected a receipt at epoch 48 with 3 confirmations, got %+v (%v)", receipt, err)
+	}
+
+	// The epoch never shows up.
+	srv = node(noEpoch)
+	start := time.Now()
+	if _, err := NewClient(srv.URL).WaitForTransaction(context.Background(), "abc", opts); !errors.Is(err, ErrNoEpoch) {
+		t.Fatalf("expected ErrNoEpoch, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("ErrNoEpoch took %v; it should not wait for the timeout", time.Since(start))
+	}
+	for res := range NewClient(srv.URL).WaitTransactions(context.Background(), []string{"abc"}, opts) {
+		if !errors.Is(res.Err, ErrNoEpoch) {
+			t.Errorf("WaitTransactions: expected ErrNoEpoch, got %v", res.Err)
+		}
+	}
+}
@@ -0,0 +1,375 @@
+// client/watch.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Direction tells whether a transfer moved funds into or out of the watched
+// address.
+type Direction string
+
+const (
+	DirectionIn   Direction = "in"
+	DirectionOut  Direction = "out"
+	DirectionSelf Direction = "self"
+)
+
+// TransferEvent is a confirmed transfer touching a watched address. Amount
+// is nil if the node's amount cannot be parsed.
+type TransferEvent struct {
+	Address      string
+	Direction    Direction
+	Counterparty string
+	Amount       *big.Int
+	Message      string
+	Epoch        uint64
+	Hash         string
+	Timestamp    json.Number
+}
+
+// Cursor marks how far a watcher has delivered events for one address: all
+// transfers up to Epoch, of which Hashes were the ones in Epoch itself.
+type Cursor struct {
+	Epoch  uint64   `json:"epoch"`
+	Hashes []string `json:"hashes,omitempty"`
+}
+
+func (c Cursor) delivered(epoch uint64, hash string) bool {
+	return epoch < c.Epoch || (epoch == c.Epoch && slices.Contains(c.Hashes, hash))
+}
+
+func (c Cursor) advance(epoch uint64, hash string) Cursor {
+	if epoch > c.Epoch {
+		return Cursor{Epoch: epoch, Hashes: []string{hash}}
+	}
+	return Cursor{Epoch: c.Epoch, Hashes: append(slices.Clip(c.Hashes), hash)}
+}
+
+// CursorStore persists watcher cursors so a restarted watcher neither
+// replays nor misses transfers. Load returns ok=false for unknown addresses.
+type CursorStore interface {
+	Load(address string) (cursor Cursor, ok bool, err error)
+	Save(address string, cursor Cursor) error
+}
+
+// MemoryCursorStore keeps cursors for the lifetime of the process.
+type MemoryCursorStore struct {
+	mu      sync.Mutex
+	cursors map[string]Cursor
+}
+
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{cursors: make(map[string]Cursor)}
+}
+
+func (m *MemoryCursorStore) Load(address string) (Cursor, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cursor, ok := m.cursors[address]
+	return cursor, ok, nil
+}
+
+func (m *MemoryCursorStore) Save(address string, cursor Cursor) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cursors[address] = cursor
+	return nil
+}
+
+// FileCursorStore keeps all cursors in one JSON file, rewritten atomically
+// on every Save.
+type FileCursorStore struct {
+	Path string
+
+	mu      sync.Mutex
+	cursors map[string]Cursor
+}
+
+func NewFileCursorStore(path string) (*FileCursorStore, error) {
+	f := &FileCursorStore{Path: path, cursors: make(map[string]Cursor)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return f, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &f.cursors); err != nil {
+		return nil, fmt.Errorf("read cursors %s: %w", path, err)
+	}
+	return f, nil
+}
+
+func (f *FileCursorStore) Load(address string) (Cursor, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cursor, ok := f.cursors[address]
+	return cursor, ok, nil
+}
+
+func (f *FileCursorStore) Save(address string, cursor Cursor) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cursors[address] = cursor
+	data, err := json.Marshal(f.cursors)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.Path), ".cursors-*")
+	if err != nil {
+		return err
+	}
+	_, werr := tmp.Write(data)
+	cerr := tmp.Close()
+	if err := errors.Join(werr, cerr); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.Path)
+}
+
+// WatchOptions configures a Watcher.
+type WatchOptions struct {
+	// Interval between polls of each address (default 5s).
+	Interval time.Duration
+	// Store persists cursors; the default keeps them in memory.
+	Store CursorStore
+	// Backfill emits the transfers that already exist when an address
+	// without a stored cursor is added. By default the watcher starts at
+	// the address's latest transfer.
+	Backfill bool
+	// PageSize is the number of recent transactions requested first; later
+	// pages double in size until the cursor is reached (default 20).
+	PageSize int
+	// OnError is called with errors that do not stop the watcher.
+	OnError func(address string, err error)
+}
+
+// maxWatchPage bounds the number of transactions requested per page.
+const maxWatchPage = 1000
+
+// Watcher polls any number of addresses and emits a TransferEvent for every
+// confirmed transfer once. Delivery is at least once: a cursor is saved
+// after its event was received, so a crash in between replays one event.
+type Watcher struct {
+	client *OctraClient
+	opts   WatchOptions
+	events chan TransferEvent
+
+	mu      sync.Mutex
+	watched map[string]bool
+	wake    chan struct{}
+}
+
+func (c *OctraClient) NewWatcher(opts WatchOptions) *Watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryCursorStore()
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 20
+	}
+	return &Watcher{
+		client:  c,
+		opts:    opts,
+		events:  make(chan TransferEvent),
+		watched: make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// WatchAddress watches a single address until ctx is done. The returned
+// channel is closed when the watcher stops.
+func (c *OctraClient) WatchAddress(ctx context.Context, address string, opts WatchOptions) <-chan TransferEvent {
+	w := c.NewWatcher(opts)
+	w.Add(address)
+	go w.Run(ctx)
+	return w.Events()
+}
+
+// Events returns the channel events are delivered on.
+func (w *Watcher) Events() <-chan TransferEvent { return w.events }
+
+// Add starts watching address; it is polled on the next round.
+func (w *Watcher) Add(address string) {
+	w.mu.Lock()
+	w.watched[address] = true
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove stops watching address. Its cursor stays in the store.
+func (w *Watcher) Remove(address string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.watched, address)
+}
+
+// Run polls until ctx is done, then closes Events and returns ctx's error.
+// It must be called once.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.events)
+	_, err := traced(ctx, w.client, "WatchAddress", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, w.run(withOperation(ctx, "WatchAddress"))
+	})
+	return err
+}
+
+func (w *Watcher) run(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		addrs := make([]string, 0, len(w.watched))
+		for addr := range w.watched {
+			addrs = append(addrs, addr)
+		}
+		w.mu.Unlock()
+		slices.Sort(addrs)
+
+		for _, addr := range addrs {
+			if err := w.poll(ctx, addr); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if w.opts.OnError != nil {
+					w.opts.OnError(addr, err)
+				}
+			}
+		}
+
+		timer := time.NewTimer(w.opts.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-w.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// poll emits the transfers of address newer than its cursor, oldest first.
+func (w *Watcher) poll(ctx context.Context, address string) error {
+	cursor, known, err := w.opts.Store.Load(address)
+	if err != nil {
+		return err
+	}
+	refs, err := w.unseen(ctx, address, cursor, known)
+	if err != nil {
+		return err
+	}
+	if !known && !w.opts.Backfill {
+		// Start at the latest transfer without emitting history.
+		for _, ref := range refs {
+			cursor = cursor.advance(uint64(ref.Epoch), ref.Hash)
+		}
+		return w.opts.Store.Save(address, cursor)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	var tip uint64
+	if w.client.depth > 1 {
+		if tip, err = w.client.CurrentEpoch(ctx); err != nil {
+			return err
+		}
+	}
+	for _, ref := range refs {
+		epoch := uint64(ref.Epoch)
+		if w.client.depth > 1 && Confirmations(epoch, tip) < w.client.depth {
+			// Later entries are at least as recent; wait for the tip.
+			return nil
+		}
+		tx, err := w.client.GetTransaction(ctx, ref.Hash)
+		if err != nil {
+			return err
+		}
+		if event, ok := newTransferEvent(address, ref, tx); ok {
+			select {
+			case w.events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		cursor = cursor.advance(epoch, ref.Hash)
+		if err := w.opts.Store.Save(address, cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unseen returns the confirmed entries of address after cursor, oldest
+// first. It pages back through the history until it reaches the cursor, so
+// a watcher that was down for long still delivers every transfer.
+func (w *Watcher) unseen(ctx context.Context, address string, cursor Cursor, known bool) ([]historyRef, error) {
+	var refs []historyRef
+	// Transfers arriving between pages shift older entries to later pages,
+	// so the same entry can be listed twice.
+	listed := make(map[string]bool)
+	offset, limit := 0, min(w.opts.PageSize, maxWatchPage)
+	for {
+		page, err := w.client.historyPage(ctx, address, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+		reached := false
+		for _, ref := range page {
+			if ref.Epoch <= 0 || listed[ref.Hash] {
+				continue // not confirmed yet, or already listed
+			}
+			listed[ref.Hash] = true
+			if known && cursor.delivered(uint64(ref.Epoch), ref.Hash) {
+				reached = true
+				continue
+			}
+			refs = append(refs, ref)
+		}
+		if len(page) < limit || reached || !known && !w.opts.Backfill {
+			slices.Reverse(refs)
+			slices.SortStableFunc(refs, func(a, b historyRef) int { return a.Epoch - b.Epoch })
+			return refs, nil
+		}
+		offset, limit = offset+limit, min(limit*2, maxWatchPage)
+	}
+}
+
+func newTransferEvent(address string, ref historyRef, tx *TransactionDetail) (TransferEvent, bool) {
+	from, to := tx.Parsed.From, tx.Parsed.To
+	event := TransferEvent{
+		Address:   address,
+		Message:   tx.Message,
+		Epoch:     uint64(ref.Epoch),
+		Hash:      ref.Hash,
+		Timestamp: tx.Parsed.Timestamp,
+	}
+	switch {
+	case strings.EqualFold(from, address) && strings.EqualFold(to, address):
+		event.Direction, event.Counterparty = DirectionSelf, address
+	case strings.EqualFold(from, address):
+		event.Direction, event.Counterparty = DirectionOut, to
+	case strings.EqualFold(to, address):
+		event.Direction, event.Counterparty = DirectionIn, from
+	default:
+		return event, false
+	}
+	event.Amount, _ = tx.Parsed.AmountAtoms()
+	return event, true
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatcherPagesBackToCursor(t *testing.T) {
+	// octW received one transfer per epoch; the watcher last saw epoch 10.
+	const epochs = 1100
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/status":
+			w.Write([]byte(`{"version":"test","epoch":1100,"features":["status","history_paging"]}`))
+		case strings.HasPrefix(r.URL.Path, "/address/"):
+			limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+			offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+			var refs []string
+			for epoch := epochs - offset; epoch > 0 && len(refs) < limit; epoch-- {
+				refs = append(refs, fmt.Sprintf(`{"hash":"h%d","epoch":%d}`, epoch, epoch))
+			}
+			fmt.Fprintf(w, `{"recent_transactions":[%s]}`, strings.Join(refs, ","))
+		case strings.HasPrefix(r.URL.Path, "/tx/"):
+			w.Write([]byte(`{"status":"confirmed","parsed_tx":{"from":"octA","to":"octW","amount":"1"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	store := NewMemoryCursorStore()
+	store.Save("octW", Cursor{Epoch: 10, Hashes: []string{"h10"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	events := NewClient(srv.URL).WatchAddress(ctx, "octW", WatchOptions{Store: store, Interval: time.Hour})
+
+	for want := uint64(11); want <= epochs; want++ {
+		select {
+		case ev := <-events:
+			if ev.Epoch != want {
+				t.Fatalf("expected epoch %d, got %+v", want, ev)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for epoch %d", want)
+		}
+	}
+}
@@ -120,8 +120,8 @@ func main() {
 	resJSON, _ := json.MarshalIndent(res, "", "  ")
 	fmt.Printf("\033[1;34m[DEBUG]\033[0m Node Response: %s\n", string(resJSON))
 
-	txHash, ok := res["tx_hash"].(string)
-	if !ok {
+	txHash := res.TxHash
+	if res.Status != "accepted" || txHash == "" {
 		fmt.Println("❌ Node Error: Transaction not accepted")
 		return
 	}
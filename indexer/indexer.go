@@ -0,0 +1,258 @@
+// Package indexer keeps the transaction history of a set of addresses in a
+// local store and serves history and stats queries from it.
+//
+//	store, _ := indexer.Open("octra-index")
+//	ix := indexer.New(oc, store, indexer.Options{})
+//	ix.Add(addr)
+//	go ix.Run(ctx)
+//	history, _ := ix.GetHistory(ctx, addr, 20)
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+// ErrNotIndexed is returned for addresses without a completed sync.
+var ErrNotIndexed = errors.New("indexer: address not indexed")
+
+// Options configures an Indexer.
+type Options struct {
+	// Interval between background syncs in Run (default 10s).
+	Interval time.Duration
+	// PageSize is the history page size used while syncing.
+	PageSize int
+	// OnError is called with sync errors in Run, which keeps going.
+	OnError func(address string, err error)
+}
+
+// Indexer syncs the full history of its addresses into a Store.
+type Indexer struct {
+	client *client.OctraClient
+	store  *Store
+	opts   Options
+
+	mu     sync.Mutex
+	addrs  map[string]bool
+	syncMu sync.Mutex // serializes Sync
+}
+
+func New(oc *client.OctraClient, store *Store, opts Options) *Indexer {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	ix := &Indexer{client: oc, store: store, opts: opts, addrs: make(map[string]bool)}
+	for _, addr := range store.Addresses() {
+		ix.addrs[addr] = true
+	}
+	return ix
+}
+
+// Store returns the indexer's store.
+func (ix *Indexer) Store() *Store { return ix.store }
+
+// Add indexes addresses from the next sync on. Addresses found in the store
+// are added by New.
+func (ix *Indexer) Add(addresses ...string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, addr := range addresses {
+		ix.addrs[addr] = true
+	}
+}
+
+// Addresses returns the indexed addresses.
+func (ix *Indexer) Addresses() []string {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	addrs := make([]string, 0, len(ix.addrs))
+	for addr := range ix.addrs {
+		addrs = append(addrs, addr)
+	}
+	slices.Sort(addrs)
+	return addrs
+}
+
+// Run syncs every Interval until ctx is done and returns ctx's error.
+func (ix *Indexer) Run(ctx context.Context) error {
+	for {
+		if err := ix.Sync(ctx); err != nil && ctx.Err() == nil && ix.opts.OnError != nil {
+			var syncErr *SyncError
+			if errors.As(err, &syncErr) {
+				for _, f := range syncErr.Failures {
+					ix.opts.OnError(f.Address, f.Err)
+				}
+			}
+		}
+		timer := time.NewTimer(ix.opts.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// SyncError lists the addresses a Sync could not bring up to date.
+type SyncError struct {
+	Failures []SyncFailure
+}
+
+type SyncFailure struct {
+	Address string
+	Err     error
+}
+
+func (e *SyncError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("%s: %v", f.Address, f.Err)
+	}
+	return "indexer: sync failed: " + strings.Join(parts, "; ")
+}
+
+func (e *SyncError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// Sync brings every address up to date once. Addresses that fail are
+// reported in a *SyncError; the others are still synced.
+func (ix *Indexer) Sync(ctx context.Context) error {
+	ix.syncMu.Lock()
+	defer ix.syncMu.Unlock()
+	var syncErr SyncError
+	for _, addr := range ix.Addresses() {
+		if err := ix.syncAddress(ctx, addr); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			syncErr.Failures = append(syncErr.Failures, SyncFailure{Address: addr, Err: err})
+		}
+	}
+	if len(syncErr.Failures) > 0 {
+		return &syncErr
+	}
+	return nil
+}
+
+// syncAddress walks the history of address down to its checkpoint, storing
+// each page as it arrives so an interrupted sync resumes without loading the
+// stored transactions again. The checkpoint only advances after a complete
+// pass.
+func (ix *Indexer) syncAddress(ctx context.Context, address string) error {
+	checkpoint, synced := ix.store.Checkpoint(address)
+	filter := client.HistoryFilter{
+		PageSize: ix.opts.PageSize,
+		Exclude:  func(hash string) bool { return ix.store.Has(address, hash) },
+	}
+	if checkpoint > 0 {
+		// Epochs are produced atomically, so everything up to the
+		// checkpoint is stored already.
+		filter.FromEpoch = checkpoint + 1
+	}
+
+	pageSize := ix.opts.PageSize
+	if pageSize <= 0 {
+		pageSize = client.DefaultHistoryPageSize
+	}
+	var page []client.TransactionHistory
+	var failed error
+	highest := checkpoint
+	flush := func() error {
+		slices.Reverse(page) // oldest first
+		err := ix.store.Append(address, page)
+		page = page[:0]
+		return err
+	}
+	for h, err := range ix.client.History(ctx, address, filter) {
+		var partial *client.HistoryError
+		switch {
+		case errors.As(err, &partial):
+			failed = err
+			continue
+		case err != nil:
+			if ferr := flush(); ferr != nil {
+				return errors.Join(err, ferr)
+			}
+			return err
+		case h.Epoch <= 0:
+			continue // not confirmed yet
+		}
+		highest = max(highest, uint64(h.Epoch))
+		if page = append(page, h); len(page) >= pageSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if failed != nil {
+		return failed
+	}
+	if !synced || highest > checkpoint {
+		return ix.store.SetCheckpoint(address, highest)
+	}
+	return nil
+}
+
+// GetHistory returns the newest limit indexed transactions of address, like
+// OctraClient.GetHistory but served from the store. A limit <= 0 returns
+// everything.
+func (ix *Indexer) GetHistory(ctx context.Context, address string, limit int) ([]client.TransactionHistory, error) {
+	if err := ix.indexed(address); err != nil {
+		return nil, err
+	}
+	recs := ix.store.Records(address)
+	if limit > 0 && len(recs) > limit {
+		recs = recs[:limit]
+	}
+	history := make([]client.TransactionHistory, len(recs))
+	for i, rec := range recs {
+		history[i] = rec.TransactionHistory
+	}
+	return history, nil
+}
+
+// GetStats sums the indexed transfers of address, like OctraClient.GetStats.
+func (ix *Indexer) GetStats(ctx context.Context, address string) (*client.WalletStats, error) {
+	if err := ix.indexed(address); err != nil {
+		return nil, err
+	}
+	stats := &client.WalletStats{TotalIn: new(big.Int), TotalOut: new(big.Int)}
+	for _, rec := range ix.store.Records(address) {
+		stats.TxCount++
+		atoms, err := rec.AmountAtoms()
+		if err != nil {
+			continue
+		}
+		switch rec.Direction(address) {
+		case client.DirectionOut, client.DirectionSelf:
+			stats.TotalOut.Add(stats.TotalOut, atoms)
+		case client.DirectionIn:
+			stats.TotalIn.Add(stats.TotalIn, atoms)
+		}
+	}
+	return stats, nil
+}
+
+func (ix *Indexer) indexed(address string) error {
+	if _, synced := ix.store.Checkpoint(address); !synced {
+		return fmt.Errorf("%w: %s", ErrNotIndexed, address)
+	}
+	return nil
+}
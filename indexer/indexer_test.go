@@ -0,0 +1,216 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+	"github.com/dayuwidayadi57/octra/octratest"
+)
+
+type txCounter struct {
+	next    http.RoundTripper
+	lookups int32
+}
+
+func (c *txCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.URL.Path, "/tx/") {
+		atomic.AddInt32(&c.lookups, 1)
+	}
+	return c.next.RoundTrip(req)
+}
+
+type wallet struct {
+	addr, priv string
+	nonce      uint64
+}
+
+func newWallet(node *octratest.Node) *wallet {
+	addr, _, priv, _ := client.GenerateNewKeyPair()
+	node.Fund(addr, 100_000_000)
+	return &wallet{addr: addr, priv: priv}
+}
+
+func (w *wallet) send(t *testing.T, oc *client.OctraClient, to string, atoms int64) {
+	t.Helper()
+	w.sendMessage(t, oc, to, atoms, "")
+}
+
+func (w *wallet) sendMessage(t *testing.T, oc *client.OctraClient, to string, atoms int64, message string) {
+	t.Helper()
+	w.nonce++
+	signed, err := client.SignTransaction(client.Transaction{
+		From:      w.addr,
+		To:        to,
+		Amount:    strconv.FormatInt(atoms, 10),
+		Nonce:     w.nonce,
+		Timestamp: json.Number(strconv.FormatInt(1737273600+int64(w.nonce), 10)),
+		Message:   message,
+	}, w.priv)
+	if err != nil {
+		t.Fatalf("Signing failed: %v", err)
+	}
+	if _, err := oc.SendTransaction(context.Background(), signed); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+}
+
+// seed gives alice 12 outgoing and 3 incoming transfers over 5 epochs.
+func seed(t *testing.T, node *octratest.Node, oc *client.OctraClient) (alice, bob *wallet) {
+	t.Helper()
+	alice, bob = newWallet(node), newWallet(node)
+	for i := 1; i <= 12; i++ {
+		alice.send(t, oc, bob.addr, int64(i*1_000))
+		if i%3 == 0 {
+			node.ProduceEpoch()
+		}
+	}
+	for i := 0; i < 3; i++ {
+		bob.send(t, oc, alice.addr, 500)
+	}
+	node.ProduceEpoch()
+	return alice, bob
+}
+
+func TestSyncServesHistoryLocally(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+	ctx := context.Background()
+	alice, bob := seed(t, node, oc)
+
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	ix := New(oc, store, Options{PageSize: 4})
+	ix.Add(alice.addr)
+	if _, err := ix.GetHistory(ctx, alice.addr, 10); !errors.Is(err, ErrNotIndexed) {
+		t.Errorf("expected ErrNotIndexed before the first sync, got %v", err)
+	}
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	remote, err := oc.GetHistory(ctx, alice.addr, 100)
+	if err != nil {
+		t.Fatalf("Remote history failed: %v", err)
+	}
+	local, err := ix.GetHistory(ctx, alice.addr, 0)
+	if err != nil || len(local) != len(remote) || len(local) != 15 {
+		t.Fatalf("expected %d local entries, got %d (%v)", len(remote), len(local), err)
+	}
+	for i := range remote {
+		if local[i].Hash != remote[i].Hash || local[i].Epoch != remote[i].Epoch {
+			t.Fatalf("entry %d differs: local %+v, remote %+v", i, local[i], remote[i])
+		}
+	}
+	if top, _ := ix.GetHistory(ctx, alice.addr, 2); len(top) != 2 || top[0].From != bob.addr {
+		t.Errorf("expected the 2 newest entries, got %+v", top)
+	}
+
+	stats, err := ix.GetStats(ctx, alice.addr)
+	if err != nil || stats.TxCount != 15 || stats.TotalOut.Int64() != 78_000 || stats.TotalIn.Int64() != 1_500 {
+		t.Errorf("Stats mismatch: %+v (%v)", stats, err)
+	}
+	if cp, _ := store.Checkpoint(alice.addr); cp != 5 {
+		t.Errorf("expected checkpoint 5, got %d", cp)
+	}
+}
+
+func TestSyncResumesAfterInterruption(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	ctx := context.Background()
+	alice, bob := seed(t, node, node.Client())
+	dir := t.TempDir()
+
+	// The first sync dies after one page of history.
+	faults := octratest.NewFaultTransport(http.DefaultTransport)
+	faults.Add(octratest.Rule{
+		Method: "GET",
+		Path:   "/address/",
+		Script: []octratest.Fault{{Kind: octratest.FaultNone}},
+		Fault:  octratest.Fault{Kind: octratest.FaultStatus, Status: http.StatusBadRequest},
+	})
+	store, _ := Open(dir)
+	ix := New(node.Client(client.WithTransport(faults)), store, Options{PageSize: 4})
+	ix.Add(alice.addr)
+	var syncErr *SyncError
+	if err := ix.Sync(ctx); !errors.As(err, &syncErr) || syncErr.Failures[0].Address != alice.addr {
+		t.Fatalf("expected a SyncError for alice, got %v", err)
+	}
+	if _, synced := store.Checkpoint(alice.addr); synced {
+		t.Fatal("checkpoint must not be set by an incomplete sync")
+	}
+
+	// After a restart only the missing transactions are loaded.
+	counter := &txCounter{next: http.DefaultTransport}
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if n := len(store.Records(alice.addr)); n != 4 {
+		t.Fatalf("expected the first page to survive, got %d records", n)
+	}
+	ix = New(node.Client(client.WithTransport(counter)), store, Options{PageSize: 4})
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatalf("Resumed sync failed: %v", err)
+	}
+	if counter.lookups != 11 {
+		t.Errorf("expected 11 lookups on resume, got %d", counter.lookups)
+	}
+
+	// Later syncs stop at the checkpoint.
+	counter.lookups = 0
+	bob.send(t, node.Client(), alice.addr, 700)
+	node.ProduceEpoch()
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatalf("Incremental sync failed: %v", err)
+	}
+	if counter.lookups != 1 {
+		t.Errorf("expected 1 lookup for the new epoch, got %d", counter.lookups)
+	}
+	if history, _ := ix.GetHistory(ctx, alice.addr, 0); len(history) != 16 || history[0].Epoch != 6 {
+		t.Errorf("expected 16 entries with epoch 6 first, got %d", len(history))
+	}
+}
+
+func TestRunKeepsIndexCurrent(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+	alice, bob := newWallet(node), newWallet(node)
+
+	store, _ := Open(t.TempDir())
+	ix := New(oc, store, Options{Interval: 20 * time.Millisecond})
+	ix.Add(bob.addr)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ix.Run(ctx) }()
+
+	alice.send(t, oc, bob.addr, 4_200)
+	node.ProduceEpoch()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats, err := ix.GetStats(ctx, bob.addr)
+		if err == nil && stats.TotalIn.Int64() == 4_200 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("index not updated: %+v (%v)", stats, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected Run to end with context.Canceled, got %v", err)
+	}
+}
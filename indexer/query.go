@@ -0,0 +1,164 @@
+// indexer/query.go
+package indexer
+
+import (
+	"cmp"
+	"context"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+// SortField selects the order of Search results.
+type SortField string
+
+const (
+	SortEpoch  SortField = "epoch"
+	SortTime   SortField = "time"
+	SortAmount SortField = "amount"
+)
+
+// Query selects indexed records. Zero fields do not filter.
+type Query struct {
+	// Address restricts the search to one indexed address; empty searches
+	// all of them.
+	Address      string
+	Counterparty string
+	Direction    client.Direction
+	// MinAmount and MaxAmount bound the amount in atoms, inclusive.
+	MinAmount *big.Int
+	MaxAmount *big.Int
+	// FromEpoch and ToEpoch bound the including epoch, inclusive.
+	FromEpoch uint64
+	ToEpoch   uint64
+	// Since and Until bound the transaction timestamp, inclusive.
+	Since time.Time
+	Until time.Time
+	// MessageContains matches a case-insensitive substring of the message.
+	MessageContains string
+	Status          string
+
+	// SortBy defaults to SortEpoch. Results are newest or largest first
+	// unless Ascending is set.
+	SortBy    SortField
+	Ascending bool
+	// Offset and Limit page through the sorted matches; a zero Limit
+	// returns all of them.
+	Offset int
+	Limit  int
+}
+
+// SearchResult is one page of matches.
+type SearchResult struct {
+	Records []Record
+	// Total is the number of matches before paging.
+	Total int
+}
+
+// Counterparty returns the other side of the transfer.
+func (r Record) Counterparty() string {
+	if r.Direction(r.Address) == client.DirectionIn {
+		return r.From
+	}
+	return r.To
+}
+
+type match struct {
+	rec    Record
+	atoms  *big.Int
+	millis int64
+}
+
+// Search returns the records matching q.
+func (s *Store) Search(q Query) SearchResult {
+	addrs := []string{q.Address}
+	if q.Address == "" {
+		addrs = s.Addresses()
+	}
+	var matches []match
+	for _, addr := range addrs {
+		for _, rec := range s.Records(addr) {
+			if m, ok := q.match(rec); ok {
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	order := func(a, b match) int { return cmp.Compare(a.rec.Epoch, b.rec.Epoch) }
+	switch q.SortBy {
+	case SortTime:
+		order = func(a, b match) int { return cmp.Compare(a.millis, b.millis) }
+	case SortAmount:
+		order = func(a, b match) int { return a.atoms.Cmp(b.atoms) }
+	}
+	if q.Ascending {
+		slices.Reverse(matches) // Records are newest first
+		slices.SortStableFunc(matches, order)
+	} else {
+		slices.SortStableFunc(matches, func(a, b match) int { return order(b, a) })
+	}
+
+	res := SearchResult{Total: len(matches)}
+	start := min(max(q.Offset, 0), len(matches))
+	end := len(matches)
+	if q.Limit > 0 {
+		end = min(start+q.Limit, end)
+	}
+	for _, m := range matches[start:end] {
+		res.Records = append(res.Records, m.rec)
+	}
+	return res
+}
+
+func (q Query) match(rec Record) (match, bool) {
+	m := match{rec: rec, atoms: new(big.Int)}
+	if q.Counterparty != "" && !strings.EqualFold(rec.Counterparty(), q.Counterparty) {
+		return m, false
+	}
+	if q.Direction != "" && rec.Direction(rec.Address) != q.Direction {
+		return m, false
+	}
+	epoch := uint64(rec.Epoch)
+	if epoch < q.FromEpoch || (q.ToEpoch > 0 && epoch > q.ToEpoch) {
+		return m, false
+	}
+	if q.Status != "" && !strings.EqualFold(rec.Status, q.Status) {
+		return m, false
+	}
+	if q.MessageContains != "" && !strings.Contains(strings.ToLower(rec.Message), strings.ToLower(q.MessageContains)) {
+		return m, false
+	}
+
+	if ts, ok := rec.Time(); ok {
+		m.millis = ts.UnixMilli()
+		if (!q.Since.IsZero() && ts.Before(q.Since)) || (!q.Until.IsZero() && ts.After(q.Until)) {
+			return m, false
+		}
+	} else if !q.Since.IsZero() || !q.Until.IsZero() {
+		return m, false
+	}
+
+	if atoms, err := rec.AmountAtoms(); err == nil {
+		m.atoms = atoms
+	} else if q.MinAmount != nil || q.MaxAmount != nil {
+		return m, false
+	}
+	if (q.MinAmount != nil && m.atoms.Cmp(q.MinAmount) < 0) || (q.MaxAmount != nil && m.atoms.Cmp(q.MaxAmount) > 0) {
+		return m, false
+	}
+	return m, true
+}
+
+// Search queries the indexed records. A query for an address without a
+// completed sync returns ErrNotIndexed.
+func (ix *Indexer) Search(ctx context.Context, q Query) (SearchResult, error) {
+	if q.Address != "" {
+		if err := ix.indexed(q.Address); err != nil {
+			return SearchResult{}, err
+		}
+	}
+	return ix.store.Search(q), nil
+}
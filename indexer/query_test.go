@@ -0,0 +1,72 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+	"github.com/dayuwidayadi57/octra/octratest"
+)
+
+func TestSearch(t *testing.T) {
+	node := octratest.NewNode()
+	defer node.Close()
+	oc := node.Client()
+	ctx := context.Background()
+	alice, bob := seed(t, node, oc)
+	carol := newWallet(node)
+	carol.sendMessage(t, oc, alice.addr, 2_500, "Invoice 42")
+	node.ProduceEpoch()
+
+	store, _ := Open(t.TempDir())
+	ix := New(oc, store, Options{})
+	if _, err := ix.Search(ctx, Query{Address: alice.addr}); !errors.Is(err, ErrNotIndexed) {
+		t.Errorf("expected ErrNotIndexed before the first sync, got %v", err)
+	}
+	ix.Add(alice.addr)
+	if err := ix.Sync(ctx); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		total int
+	}{
+		{"all", Query{Address: alice.addr}, 16},
+		{"counterparty", Query{Counterparty: carol.addr}, 1},
+		{"incoming", Query{Address: alice.addr, Direction: client.DirectionIn}, 4},
+		{"amount range", Query{Direction: client.DirectionOut, MinAmount: big.NewInt(5_000), MaxAmount: big.NewInt(8_000)}, 4},
+		{"epoch range", Query{Counterparty: bob.addr, FromEpoch: 2, ToEpoch: 3}, 6},
+		{"since", Query{Since: time.Unix(1737273610, 0)}, 3},
+		{"message", Query{MessageContains: "invoice"}, 1},
+		{"status", Query{Status: "Confirmed"}, 16},
+		{"no match", Query{Counterparty: bob.addr, MessageContains: "invoice"}, 0},
+	}
+	for _, tt := range tests {
+		res, err := ix.Search(ctx, tt.query)
+		if err != nil || res.Total != tt.total || len(res.Records) != tt.total {
+			t.Errorf("%s: expected %d matches, got %d/%d (%v)", tt.name, tt.total, len(res.Records), res.Total, err)
+		}
+	}
+
+	res, _ := ix.Search(ctx, Query{SortBy: SortAmount, Limit: 3})
+	if res.Total != 16 || len(res.Records) != 3 || atoms(res.Records[0]) != 12_000 || atoms(res.Records[2]) != 10_000 {
+		t.Errorf("expected the 3 largest transfers, got %+v", res.Records)
+	}
+	res, _ = ix.Search(ctx, Query{Ascending: true, Offset: 14, Limit: 5})
+	if len(res.Records) != 2 || res.Records[1].From != carol.addr {
+		t.Errorf("expected the last page to end with carol's transfer, got %+v", res.Records)
+	}
+	if got := res.Records[1].Counterparty(); got != carol.addr {
+		t.Errorf("expected counterparty %s, got %s", carol.addr, got)
+	}
+}
+
+func atoms(rec Record) int64 {
+	v, _ := rec.AmountAtoms()
+	return v.Int64()
+}
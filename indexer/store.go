@@ -0,0 +1,244 @@
+// indexer/store.go
+package indexer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+// Record is an indexed transaction of Address.
+type Record struct {
+	Address string `json:"address"`
+	client.TransactionHistory
+}
+
+// Store is the embedded on-disk store of an Indexer. Each address has an
+// append-only JSON-lines file of records; checkpoints live in one file that
+// is replaced atomically after the records it covers are synced to disk.
+type Store struct {
+	dir string
+
+	mu          sync.RWMutex
+	records     map[string][]Record
+	hashes      map[string]map[string]bool
+	checkpoints map[string]uint64
+}
+
+const checkpointFile = "checkpoints.json"
+
+// Open loads the store in dir, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		dir:         dir,
+		records:     make(map[string][]Record),
+		hashes:      make(map[string]map[string]bool),
+		checkpoints: make(map[string]uint64),
+	}
+	data, err := os.ReadFile(filepath.Join(dir, checkpointFile))
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &s.checkpoints); err != nil {
+			return nil, fmt.Errorf("read checkpoints: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		if err := s.load(name); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Store) load(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	// A crash can leave a torn last line. Cut it off so the next Append
+	// starts on a fresh line; its records are synced again later.
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(name, int64(end)); err != nil {
+			return err
+		}
+		data = data[:end]
+	}
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		var rec Record
+		if json.Unmarshal(line, &rec) != nil || rec.Hash == "" {
+			continue
+		}
+		s.add(rec)
+	}
+	return nil
+}
+
+func (s *Store) add(rec Record) bool {
+	seen := s.hashes[rec.Address]
+	if seen == nil {
+		seen = make(map[string]bool)
+		s.hashes[rec.Address] = seen
+	}
+	if seen[rec.Hash] {
+		return false
+	}
+	seen[rec.Hash] = true
+	s.records[rec.Address] = append(s.records[rec.Address], rec)
+	return true
+}
+
+func (s *Store) path(address string) string {
+	sum := sha256.Sum256([]byte(address))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+".jsonl")
+}
+
+// Has reports whether hash is stored for address.
+func (s *Store) Has(address, hash string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hashes[address][hash]
+}
+
+// Append stores the records of address that are not stored yet and syncs
+// them to disk. Records only become visible once they are on disk, so a
+// failed Append leaves nothing behind for the next sync to skip.
+func (s *Store) Append(address string, history []client.TransactionHistory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		recs []Record
+		buf  bytes.Buffer
+	)
+	pending := make(map[string]bool)
+	for _, h := range history {
+		if s.hashes[address][h.Hash] || pending[h.Hash] {
+			continue
+		}
+		pending[h.Hash] = true
+		rec := Record{Address: address, TransactionHistory: h}
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		recs = append(recs, rec)
+	}
+	if len(recs) == 0 {
+		return nil
+	}
+	if err := appendFile(s.path(address), buf.Bytes()); err != nil {
+		return err
+	}
+	for _, rec := range recs {
+		s.add(rec)
+	}
+	return nil
+}
+
+// appendFile appends data to name and syncs it. A failed write is cut off
+// again so the file keeps ending on a complete line.
+func appendFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	_, werr := f.Write(data)
+	serr := f.Sync()
+	if werr != nil || serr != nil {
+		f.Truncate(info.Size())
+	}
+	return errors.Join(werr, serr, f.Close())
+}
+
+// Checkpoint returns the epoch up to which address is fully indexed, and
+// false if no sync of address has completed yet.
+func (s *Store) Checkpoint(address string) (uint64, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	epoch, ok := s.checkpoints[address]
+	return epoch, ok
+}
+
+// SetCheckpoint records that address is fully indexed up to epoch.
+func (s *Store) SetCheckpoint(address string, epoch uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[address] = epoch
+	data, err := json.Marshal(s.checkpoints)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".checkpoints-*")
+	if err != nil {
+		return err
+	}
+	_, werr := tmp.Write(data)
+	serr := tmp.Sync()
+	cerr := tmp.Close()
+	if err := errors.Join(werr, serr, cerr); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, checkpointFile))
+}
+
+// Records returns the records of address, newest first.
+func (s *Store) Records(address string) []Record {
+	s.mu.RLock()
+	recs := slices.Clone(s.records[address])
+	s.mu.RUnlock()
+	// A resumed sync may append older records after newer ones, so order by
+	// epoch and timestamp and keep append order within.
+	slices.SortStableFunc(recs, func(a, b Record) int {
+		if a.Epoch != b.Epoch {
+			return a.Epoch - b.Epoch
+		}
+		ta, _ := a.Time()
+		tb, _ := b.Time()
+		return ta.Compare(tb)
+	})
+	slices.Reverse(recs)
+	return recs
+}
+
+// Addresses returns every address with stored records or a checkpoint.
+func (s *Store) Addresses() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var addrs []string
+	for addr := range s.checkpoints {
+		addrs = append(addrs, addr)
+	}
+	for addr := range s.records {
+		if _, ok := s.checkpoints[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	slices.Sort(addrs)
+	return addrs
+}
@@ -0,0 +1,59 @@
+package indexer
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+func TestAppendKeepsOnlyDurableRecords(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	tx := func(hash string, epoch int) client.TransactionHistory {
+		return client.TransactionHistory{Hash: hash, Epoch: epoch, From: "octA", To: "octB", Amount: "1", Timestamp: json.Number("1737273600")}
+	}
+
+	// A record file that cannot be written must not mark records as stored.
+	if err := os.Mkdir(store.path("octA"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append("octA", []client.TransactionHistory{tx("h1", 1)}); err == nil {
+		t.Fatal("expected Append to fail")
+	}
+	if store.Has("octA", "h1") || len(store.Records("octA")) != 0 {
+		t.Fatal("failed Append must not keep records in memory")
+	}
+	os.Remove(store.path("octA"))
+
+	if err := store.Append("octA", []client.TransactionHistory{tx("h1", 1), tx("h2", 1), tx("h1", 1)}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	// A crash mid-write leaves a torn line; reopening cuts it off so the
+	// next record starts on its own line.
+	f, err := os.OpenFile(store.path("octA"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"address":"octA","hash":"h3","ep`)
+	f.Close()
+	store, err = Open(dir)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if store.Has("octA", "h3") {
+		t.Error("torn record must not be loaded")
+	}
+	if err := store.Append("octA", []client.TransactionHistory{tx("h3", 2)}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	store, _ = Open(dir)
+	if recs := store.Records("octA"); len(recs) != 3 || recs[0].Hash != "h3" {
+		t.Errorf("expected 3 records with h3 first, got %+v", recs)
+	}
+}
@@ -0,0 +1,224 @@
+// octratest/cassette.go
+package octratest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards every request upstream and records it.
+	ModeRecord
+	// ModeRecordOnce replays when the cassette file exists and records otherwise.
+	ModeRecordOnce
+)
+
+// Interaction is a single recorded request/response pair.
+type Interaction struct {
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	RequestBody  string            `json:"request_body,omitempty"`
+	Status       int               `json:"status"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	ResponseBody string            `json:"response_body"`
+}
+
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper for client.WithTransport that records
+// node interactions to a JSON cassette and replays them deterministically.
+type Recorder struct {
+	Mode      Mode
+	File      string
+	Transport http.RoundTripper
+
+	// MatchBody also compares request bodies when replaying.
+	MatchBody bool
+	// ScrubSignatures replaces signatures and public keys in recorded bodies.
+	ScrubSignatures bool
+	// ScrubAddresses replaces oct addresses with stable placeholders, in
+	// order of first appearance, in paths and bodies.
+	ScrubAddresses bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+	aliases  map[string]string
+}
+
+var ErrNoInteraction = errors.New("octratest: no recorded interaction matches request")
+
+var (
+	addressPattern   = regexp.MustCompile(`oct[1-9A-HJ-NP-Za-km-z]{32,50}`)
+	signaturePattern = regexp.MustCompile(`"(signature|public_key)"\s*:\s*"[^"]*"`)
+)
+
+func NewRecorder(file string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Mode: mode, File: file, Transport: http.DefaultTransport}
+	if mode == ModeRecordOnce {
+		if _, err := os.Stat(file); err == nil {
+			r.Mode = ModeReplay
+		} else {
+			r.Mode = ModeRecord
+		}
+	}
+	if r.Mode == ModeReplay {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("decode cassette %s: %w", file, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	r.mu.Lock()
+	path := r.scrub(req.URL.RequestURI())
+	reqBody := r.scrub(string(body))
+	r.mu.Unlock()
+
+	if r.Mode == ModeReplay {
+		return r.replay(req, path, reqBody)
+	}
+
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	it := Interaction{
+		Method:       req.Method,
+		Path:         path,
+		RequestBody:  reqBody,
+		Status:       resp.StatusCode,
+		ResponseBody: r.scrub(string(respBody)),
+	}
+	for _, h := range []string{"Content-Type", "Retry-After"} {
+		if v := resp.Header.Get(h); v != "" {
+			if it.Headers == nil {
+				it.Headers = make(map[string]string)
+			}
+			it.Headers[h] = v
+		}
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// replay serves the first unused matching interaction. Once every match has
+// been used the last one keeps being served, so polling loops settle.
+func (r *Recorder) replay(req *http.Request, path, body string) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, it := range r.cassette.Interactions {
+		if it.Method != req.Method || it.Path != path || (r.MatchBody && it.RequestBody != body) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return it.response(req), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return r.cassette.Interactions[last].response(req), nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, path)
+}
+
+func (it Interaction) response(req *http.Request) *http.Response {
+	header := make(http.Header)
+	for k, v := range it.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", it.Status, http.StatusText(it.Status)),
+		StatusCode:    it.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(it.ResponseBody)),
+		ContentLength: int64(len(it.ResponseBody)),
+		Request:       req,
+	}
+}
+
+// Save writes the recorded interactions to File. It is a no-op in replay mode.
+func (r *Recorder) Save() error {
+	if r.Mode == ModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.File, data, 0o644)
+}
+
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+func (r *Recorder) scrub(s string) string {
+	if r.ScrubSignatures {
+		s = signaturePattern.ReplaceAllString(s, `"$1":"<scrubbed>"`)
+	}
+	if r.ScrubAddresses {
+		s = addressPattern.ReplaceAllStringFunc(s, func(addr string) string {
+			if r.aliases == nil {
+				r.aliases = make(map[string]string)
+			}
+			alias, ok := r.aliases[addr]
+			if !ok {
+				alias = fmt.Sprintf("octADDRESS%d", len(r.aliases)+1)
+				r.aliases[addr] = alias
+			}
+			return alias
+		})
+	}
+	return s
+}
@@ -0,0 +1,63 @@
+package octratest
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "transfer.json")
+	ctx := context.Background()
+
+	sender, _, priv, _ := client.GenerateNewKeyPair()
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	signed, _ := client.SignTransaction(client.Transaction{
+		From: sender, To: receiver, Amount: "1000000", Nonce: 1, Timestamp: json.Number("1737273600"),
+	}, priv)
+
+	node := NewNode()
+	node.Fund(sender, 5_000_000)
+	rec, err := NewRecorder(file, ModeRecordOnce)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	rec.ScrubSignatures = true
+
+	oc := node.Client(client.WithTransport(rec))
+	res, err := oc.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	node.ProduceEpoch()
+	if _, err := oc.GetTransaction(ctx, res.TxHash); err != nil {
+		t.Fatalf("GetTransaction failed: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	node.Close()
+
+	for _, it := range rec.Interactions() {
+		if strings.Contains(it.RequestBody, signed.Signature) || strings.Contains(it.ResponseBody, signed.Signature) {
+			t.Errorf("signature leaked into cassette: %+v", it)
+		}
+	}
+
+	replay, err := NewRecorder(file, ModeRecordOnce)
+	if err != nil || replay.Mode != ModeReplay {
+		t.Fatalf("expected replay mode from existing cassette, got %v (%v)", replay, err)
+	}
+	offline := client.NewClient("http://octra.invalid", client.WithTransport(replay))
+	if _, err := offline.SendTransaction(ctx, signed); err != nil {
+		t.Fatalf("Replayed send failed: %v", err)
+	}
+	tx, err := offline.GetTransaction(ctx, res.TxHash)
+	if err != nil || tx.Epoch != 1 || tx.Parsed.From != sender {
+		t.Fatalf("Replayed tx mismatch: %+v (%v)", tx, err)
+	}
+}
@@ -0,0 +1,233 @@
+// octratest/faults.go
+package octratest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+type FaultKind int
+
+const (
+	// FaultNone passes the request through untouched.
+	FaultNone FaultKind = iota
+	// FaultLatency delays the request by Delay before forwarding it.
+	FaultLatency
+	// FaultTimeout hangs until the request context ends or Delay passes and
+	// then fails with a network timeout.
+	FaultTimeout
+	// FaultReset fails the request with a connection reset.
+	FaultReset
+	// FaultStatus answers with Status (e.g. 429 or 503) without forwarding.
+	FaultStatus
+	// FaultTruncate forwards the request but cuts the response body short.
+	FaultTruncate
+	// FaultMalformed forwards the request but replaces the body with junk.
+	FaultMalformed
+	// FaultDropTx answers POST /send-tx as accepted without forwarding it, so
+	// the transaction never confirms.
+	FaultDropTx
+)
+
+type Fault struct {
+	Kind       FaultKind
+	Delay      time.Duration
+	Status     int
+	RetryAfter string
+}
+
+// Rule selects requests by method, path prefix and, when Host is set, the
+// host[:port] they are sent to, and decides which fault they get. Host lets
+// a test fail one node of a multi-endpoint client. Script is consumed one entry per matching request; once it is
+// exhausted (or when it is empty) Fault is injected with Probability, where
+// a zero Probability means always. Times caps how often the rule fires.
+type Rule struct {
+	Method      string
+	Host        string
+	Path        string
+	Script      []Fault
+	Fault       Fault
+	Probability float64
+	Times       int
+}
+
+// FaultTransport is an http.RoundTripper for client.WithTransport that
+// injects failures in front of a real transport.
+type FaultTransport struct {
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	rules    []*ruleState
+	rng      *rand.Rand
+	injected int
+}
+
+type ruleState struct {
+	Rule
+	matched int
+	fired   int
+}
+
+func NewFaultTransport(next http.RoundTripper) *FaultTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &FaultTransport{Transport: next, rng: rand.New(rand.NewPCG(1, 2))}
+}
+
+// Seed makes probabilistic rules reproducible.
+func (f *FaultTransport) Seed(seed uint64) {
+	f.mu.Lock()
+	f.rng = rand.New(rand.NewPCG(seed, seed))
+	f.mu.Unlock()
+}
+
+func (f *FaultTransport) Add(rule Rule) {
+	f.mu.Lock()
+	f.rules = append(f.rules, &ruleState{Rule: rule})
+	f.mu.Unlock()
+}
+
+// Injected returns how many faults have been injected so far.
+func (f *FaultTransport) Injected() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.injected
+}
+
+func (f *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := f.next(req)
+	switch fault.Kind {
+	case FaultLatency:
+		if err := wait(req, fault.Delay); err != nil {
+			return nil, err
+		}
+	case FaultTimeout:
+		if err := wait(req, fault.Delay); err != nil {
+			return nil, err
+		}
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: timeoutError{}}
+	case FaultReset:
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	case FaultStatus:
+		resp := synthetic(req, fault.Status, `{"error":"injected fault"}`)
+		if fault.RetryAfter != "" {
+			resp.Header.Set("Retry-After", fault.RetryAfter)
+		}
+		return resp, nil
+	case FaultDropTx:
+		return dropTx(req)
+	}
+
+	resp, err := f.Transport.RoundTrip(req)
+	if err != nil || (fault.Kind != FaultTruncate && fault.Kind != FaultMalformed) {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if fault.Kind == FaultMalformed {
+		resp.Body = io.NopCloser(strings.NewReader(`{"status": <html>bad gateway`))
+	} else {
+		resp.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body[:len(body)/2]), errReader{io.ErrUnexpectedEOF}))
+	}
+	resp.ContentLength = -1
+	return resp, nil
+}
+
+func (f *FaultTransport) next(req *http.Request) Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.rules {
+		if (r.Method != "" && r.Method != req.Method) || (r.Host != "" && r.Host != req.URL.Host) ||
+			!strings.HasPrefix(req.URL.Path, r.Path) {
+			continue
+		}
+		if r.Times > 0 && r.fired >= r.Times {
+			continue
+		}
+		r.matched++
+		fault := r.Fault
+		if r.matched <= len(r.Script) {
+			fault = r.Script[r.matched-1]
+		} else if r.Probability > 0 && f.rng.Float64() >= r.Probability {
+			fault = Fault{}
+		}
+		if fault.Kind == FaultNone {
+			continue
+		}
+		r.fired++
+		f.injected++
+		return fault
+	}
+	return Fault{}
+}
+
+func wait(req *http.Request, d time.Duration) error {
+	if d <= 0 {
+		<-req.Context().Done()
+		return req.Context().Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func dropTx(req *http.Request) (*http.Response, error) {
+	var tx client.Transaction
+	if req.Body != nil {
+		if err := json.NewDecoder(req.Body).Decode(&tx); err != nil {
+			return synthetic(req, http.StatusBadRequest, `{"error":"malformed transaction"}`), nil
+		}
+	}
+	canonical, err := client.CanonicalPayload(tx)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(canonical)
+	body, _ := json.Marshal(map[string]string{"status": "accepted", "tx_hash": hex.EncodeToString(sum[:])})
+	return synthetic(req, http.StatusOK, string(body)), nil
+}
+
+func synthetic(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout (injected)" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
@@ -0,0 +1,140 @@
+package octratest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+func faultyClient(node *Node, ft *FaultTransport) *client.OctraClient {
+	return node.Client(
+		client.WithTransport(ft),
+		client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}),
+	)
+}
+
+func TestFaultInjection(t *testing.T) {
+	node := NewNode()
+	defer node.Close()
+	ctx := context.Background()
+
+	sender, _, priv, _ := client.GenerateNewKeyPair()
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	node.Fund(sender, 5_000_000)
+	sign := func(nonce uint64) *client.SignedTransaction {
+		s, _ := client.SignTransaction(client.Transaction{
+			From: sender, To: receiver, Amount: "1000000", Nonce: nonce, Timestamp: json.Number("1737273600"),
+		}, priv)
+		return s
+	}
+
+	ft := NewFaultTransport(nil)
+	ft.Add(Rule{Method: "POST", Path: "/send-tx", Script: []Fault{{Kind: FaultReset}}})
+	ft.Add(Rule{Method: "GET", Path: "/tx/", Script: []Fault{
+		{Kind: FaultStatus, Status: http.StatusServiceUnavailable},
+		{Kind: FaultTruncate},
+		{Kind: FaultLatency, Delay: 5 * time.Millisecond},
+	}})
+	oc := faultyClient(node, ft)
+
+	res, err := oc.SendTransaction(ctx, sign(1))
+	if err != nil {
+		t.Fatalf("send should survive a connection reset: %v", err)
+	}
+	node.ProduceEpoch()
+
+	history, err := oc.GetHistory(ctx, receiver, 10)
+	if err != nil || len(history) != 1 || history[0].Hash != res.TxHash {
+		t.Fatalf("history should survive transient faults: %+v (%v)", history, err)
+	}
+	if ft.Injected() < 3 {
+		t.Errorf("expected scripted faults to fire, injected %d", ft.Injected())
+	}
+
+	ft.Add(Rule{Method: "GET", Path: "/balance/", Fault: Fault{Kind: FaultMalformed}})
+	if _, err := oc.GetBalance(ctx, sender); err == nil {
+		t.Errorf("malformed body must surface as an error")
+	}
+
+	ft.Add(Rule{Method: "POST", Path: "/send-tx", Fault: Fault{Kind: FaultDropTx}})
+	dropped, err := oc.SendTransaction(ctx, sign(2))
+	if err != nil {
+		t.Fatalf("dropped tx should look accepted: %v", err)
+	}
+	node.ProduceEpoch()
+	if _, err := oc.GetTransaction(ctx, dropped.TxHash); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("dropped tx must never appear on the node, got %v", err)
+	}
+}
+
+func TestWaitOnDroppedTransaction(t *testing.T) {
+	node := NewNode()
+	defer node.Close()
+	ctx := context.Background()
+
+	sender, _, priv, _ := client.GenerateNewKeyPair()
+	receiver, _, _, _ := client.GenerateNewKeyPair()
+	node.Fund(sender, 5_000_000)
+	signed, _ := client.SignTransaction(client.Transaction{
+		From: sender, To: receiver, Amount: "1000000", Nonce: 1, Timestamp: json.Number("1737273600"),
+	}, priv)
+
+	ft := NewFaultTransport(nil)
+	ft.Add(Rule{Method: "POST", Path: "/send-tx", Fault: Fault{Kind: FaultDropTx}})
+	oc := faultyClient(node, ft)
+	res, err := oc.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatalf("dropped tx should look accepted: %v", err)
+	}
+	node.ProduceEpoch()
+
+	start := time.Now()
+	opts := client.WaitOptions{Timeout: 200 * time.Millisecond, Interval: 10 * time.Millisecond}
+	if _, err := oc.WaitForTransaction(ctx, res.TxHash, opts); !errors.Is(err, client.ErrWaitTimeout) {
+		t.Fatalf("expected ErrWaitTimeout for a transaction that never confirms, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("wait did not stop at its timeout: %v", elapsed)
+	}
+	if _, err := oc.WaitTransaction(ctx, res.TxHash, 100*time.Millisecond); !errors.Is(err, client.ErrWaitTimeout) {
+		t.Errorf("expected WaitTransaction to time out, got %v", err)
+	}
+}
+
+func TestFaultPerEndpoint(t *testing.T) {
+	down, up := NewNode(), NewNode()
+	defer down.Close()
+	defer up.Close()
+	downURL, _ := url.Parse(down.URL())
+
+	ft := NewFaultTransport(nil)
+	ft.Add(Rule{Host: downURL.Host, Fault: Fault{Kind: FaultStatus, Status: http.StatusBadGateway}})
+	oc := client.NewMultiClient([]string{down.URL(), up.URL()},
+		client.WithTransport(ft),
+		client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}),
+	)
+
+	for i := 0; i < 5; i++ {
+		if _, err := oc.GetBalance(context.Background(), "oct1"); err != nil {
+			t.Fatalf("request %d should fail over to the healthy node: %v", i, err)
+		}
+	}
+	for _, st := range oc.Endpoints().Stats() {
+		switch st.URL {
+		case down.URL():
+			if st.Failures == 0 {
+				t.Errorf("expected faults on %s, got %+v", st.URL, st)
+			}
+		case up.URL():
+			if st.Failures != 0 {
+				t.Errorf("the rule must not hit %s, got %+v", st.URL, st)
+			}
+		}
+	}
+}
@@ -0,0 +1,486 @@
+/*
+ * Octra Go SDK - In-process test node
+ *
+ * Package octratest starts an httptest.Server that speaks the Octra RPC
+ * protocol with real ledger semantics: OTX-1 signatures are verified, nonces
+ * and balances are enforced, and accepted transactions are staged until an
+ * epoch is produced, either on demand or on a timer.
+ */
+
+package octratest
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dayuwidayadi57/octra/client"
+)
+
+type Node struct {
+	Server *httptest.Server
+
+	mu       sync.Mutex
+	accounts map[string]*account
+	txs      map[string]*record
+	staged   []*record
+	epochs   [][]string
+	stop     chan struct{}
+	produced chan struct{} // closed and replaced on every new epoch
+	disabled map[string]bool
+}
+
+type account struct {
+	balance *big.Int
+	nonce   uint64
+	hasKey  bool
+	history []string
+}
+
+type record struct {
+	hash      string
+	tx        client.Transaction
+	amount    *big.Int
+	signature string
+	publicKey string
+	epoch     uint64
+}
+
+func NewNode() *Node {
+	n := &Node{
+		accounts: make(map[string]*account),
+		txs:      make(map[string]*record),
+		stop:     make(chan struct{}),
+		produced: make(chan struct{}),
+		disabled: make(map[string]bool),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /balance/{addr}", n.handleBalance)
+	mux.HandleFunc("POST /send-tx", n.handleSendTx)
+	mux.HandleFunc("GET /tx/{hash}", n.handleTx)
+	mux.HandleFunc("GET /address/{addr}", n.handleAddress)
+	mux.HandleFunc("GET /status", n.handleStatus)
+	mux.HandleFunc("GET /epoch/{n}", n.handleEpoch)
+	mux.HandleFunc("GET /staging", n.handleStaging)
+	mux.HandleFunc("POST /balances", n.handleBalances)
+	mux.HandleFunc("GET /epochs/stream", n.handleEpochStream)
+	n.Server = httptest.NewServer(mux)
+	return n
+}
+
+func (n *Node) URL() string { return n.Server.URL }
+
+// Client returns an OctraClient pointed at the node.
+func (n *Node) Client(opts ...client.Option) *client.OctraClient {
+	return client.NewClient(n.Server.URL, opts...)
+}
+
+func (n *Node) Close() {
+	n.mu.Lock()
+	select {
+	case <-n.stop:
+	default:
+		close(n.stop)
+	}
+	n.mu.Unlock()
+	n.Server.Close()
+}
+
+// Fund credits atoms to addr.
+func (n *Node) Fund(addr string, atoms int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	acc := n.account(addr)
+	acc.balance.Add(acc.balance, big.NewInt(atoms))
+}
+
+// Epoch returns the number of the last produced epoch (0 before the first).
+func (n *Node) Epoch() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return uint64(len(n.epochs))
+}
+
+// ProduceEpoch applies every staged transaction to the ledger in a new
+// epoch and returns its number.
+func (n *Node) ProduceEpoch() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	epoch := uint64(len(n.epochs)) + 1
+	hashes := make([]string, 0, len(n.staged))
+	for _, rec := range n.staged {
+		from, to := n.account(rec.tx.From), n.account(rec.tx.To)
+		from.balance.Sub(from.balance, rec.amount)
+		to.balance.Add(to.balance, rec.amount)
+		if rec.tx.Nonce > from.nonce {
+			from.nonce = rec.tx.Nonce
+		}
+		from.hasKey = true
+		rec.epoch = epoch
+		from.history = append(from.history, rec.hash)
+		if rec.tx.To != rec.tx.From {
+			to.history = append(to.history, rec.hash)
+		}
+		hashes = append(hashes, rec.hash)
+	}
+	n.staged = nil
+	n.epochs = append(n.epochs, hashes)
+	close(n.produced)
+	n.produced = make(chan struct{})
+	return epoch
+}
+
+// Disable removes optional features ("staging", "epochs", "batch_balance",
+// "epoch_stream", "history_paging") from /status and makes their routes answer like a node
+// build without them.
+func (n *Node) Disable(features ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, f := range features {
+		n.disabled[f] = true
+	}
+}
+
+// StartEpochs produces an epoch every interval until the node is closed.
+func (n *Node) StartEpochs(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-n.stop:
+				return
+			case <-ticker.C:
+				n.ProduceEpoch()
+			}
+		}
+	}()
+}
+
+func (n *Node) account(addr string) *account {
+	acc, ok := n.accounts[addr]
+	if !ok {
+		acc = &account{balance: new(big.Int)}
+		n.accounts[addr] = acc
+	}
+	return acc
+}
+
+// pending returns the highest staged nonce and total staged outflow of addr.
+func (n *Node) pending(addr string) (uint64, *big.Int) {
+	var nonce uint64
+	out := new(big.Int)
+	for _, rec := range n.staged {
+		if rec.tx.From == addr {
+			nonce = max(nonce, rec.tx.Nonce)
+			out.Add(out, rec.amount)
+		}
+	}
+	return nonce, out
+}
+
+func (n *Node) handleBalance(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	writeJSON(w, http.StatusOK, n.balance(r.PathValue("addr")))
+}
+
+func (n *Node) balance(addr string) map[string]interface{} {
+	acc := n.account(addr)
+	return map[string]interface{}{
+		"address":        addr,
+		"balance":        client.FromAtoms(acc.balance),
+		"balance_raw":    acc.balance.String(),
+		"nonce":          acc.nonce,
+		"has_public_key": acc.hasKey,
+	}
+}
+
+func (n *Node) handleBalances(w http.ResponseWriter, r *http.Request) {
+	if n.off("batch_balance", w, r) {
+		return
+	}
+	var req struct {
+		Addresses []string `json:"addresses"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed request: "+err.Error())
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	balances := make([]map[string]interface{}, 0, len(req.Addresses))
+	for _, addr := range req.Addresses {
+		balances = append(balances, n.balance(addr))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"balances": balances})
+}
+
+func (n *Node) handleSendTx(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		From      string      `json:"from"`
+		To        string      `json:"to_"`
+		Amount    string      `json:"amount"`
+		Nonce     uint64      `json:"nonce"`
+		OU        string      `json:"ou"`
+		Timestamp json.Number `json:"timestamp"`
+		Message   string      `json:"message"`
+		Signature string      `json:"signature"`
+		PublicKey string      `json:"public_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "malformed transaction: "+err.Error())
+		return
+	}
+	tx := client.Transaction{
+		From: req.From, To: req.To, Amount: req.Amount, Nonce: req.Nonce,
+		OU: req.OU, Timestamp: req.Timestamp, Message: req.Message,
+	}
+
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid amount")
+		return
+	}
+	canonical, err := client.CanonicalPayload(tx)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pub, perr := base64.StdEncoding.DecodeString(req.PublicKey)
+	sig, serr := base64.StdEncoding.DecodeString(req.Signature)
+	if perr != nil || serr != nil || len(pub) != ed25519.PublicKeySize ||
+		client.PublicKeyToAddress(pub) != req.From || !ed25519.Verify(pub, canonical, sig) {
+		writeError(w, http.StatusBadRequest, "invalid signature")
+		return
+	}
+	sum := sha256.Sum256(canonical)
+	hash := hex.EncodeToString(sum[:])
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, exists := n.txs[hash]; exists {
+		writeError(w, http.StatusConflict, "duplicate transaction")
+		return
+	}
+	acc := n.account(req.From)
+	stagedNonce, stagedOut := n.pending(req.From)
+	if req.Nonce <= max(acc.nonce, stagedNonce) {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("nonce too low: expected > %d", max(acc.nonce, stagedNonce)))
+		return
+	}
+	if new(big.Int).Add(stagedOut, amount).Cmp(acc.balance) > 0 {
+		writeError(w, http.StatusBadRequest, "insufficient balance")
+		return
+	}
+
+	rec := &record{hash: hash, tx: tx, amount: amount, signature: req.Signature, publicKey: req.PublicKey}
+	n.txs[hash] = rec
+	n.staged = append(n.staged, rec)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "accepted",
+		"tx_hash": hash,
+		"ou_cost": tx.OU,
+	})
+}
+
+func (n *Node) handleTx(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	rec, ok := n.txs[r.PathValue("hash")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "tx not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, rec.detail())
+}
+
+func (n *Node) handleAddress(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	addr := r.PathValue("addr")
+	acc := n.account(addr)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset := 0
+	if !n.disabled["history_paging"] {
+		offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	}
+	recent := make([]map[string]interface{}, 0, len(acc.history))
+	for i := len(acc.history) - 1 - max(offset, 0); i >= 0; i-- {
+		if limit > 0 && len(recent) >= limit {
+			break
+		}
+		h := acc.history[i]
+		recent = append(recent, map[string]interface{}{"hash": h, "epoch": n.txs[h].epoch})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"address":             addr,
+		"balance":             client.FromAtoms(acc.balance),
+		"nonce":               acc.nonce,
+		"transaction_count":   len(acc.history),
+		"recent_transactions": recent,
+	})
+}
+
+func (n *Node) handleStatus(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"version":      "octratest",
+		"network":      "devnet",
+		"epoch":        len(n.epochs),
+		"staged_count": len(n.staged),
+		"features":     n.features(),
+	})
+}
+
+func (n *Node) features() []string {
+	var features []string
+	for _, f := range []string{"status", "staging", "epochs", "batch_balance", "epoch_stream", "history_paging"} {
+		if !n.disabled[f] {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// off answers like the router of a build without feature when it is
+// disabled.
+func (n *Node) off(feature string, w http.ResponseWriter, r *http.Request) bool {
+	n.mu.Lock()
+	disabled := n.disabled[feature]
+	n.mu.Unlock()
+	if disabled {
+		http.NotFound(w, r)
+	}
+	return disabled
+}
+
+// handleEpochStream announces the current and every new epoch number as
+// server-sent events.
+func (n *Node) handleEpochStream(w http.ResponseWriter, r *http.Request) {
+	if n.off("epoch_stream", w, r) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for {
+		n.mu.Lock()
+		epoch, produced := len(n.epochs), n.produced
+		n.mu.Unlock()
+		fmt.Fprintf(w, "event: epoch\ndata: {\"epoch\":%d}\n\n", epoch)
+		flusher.Flush()
+		select {
+		case <-produced:
+		case <-r.Context().Done():
+			return
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+func (n *Node) handleEpoch(w http.ResponseWriter, r *http.Request) {
+	if n.off("epochs", w, r) {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	num, err := strconv.ParseUint(r.PathValue("n"), 10, 64)
+	if err != nil || num == 0 || num > uint64(len(n.epochs)) {
+		writeError(w, http.StatusNotFound, "epoch not found")
+		return
+	}
+	txs := make([]map[string]interface{}, 0, len(n.epochs[num-1]))
+	for _, h := range n.epochs[num-1] {
+		txs = append(txs, n.txs[h].detail())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"epoch":        num,
+		"transactions": txs,
+	})
+}
+
+func (n *Node) handleStaging(w http.ResponseWriter, r *http.Request) {
+	if n.off("staging", w, r) {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	from := r.URL.Query().Get("from")
+	staged := make([]map[string]interface{}, 0, len(n.staged))
+	for _, rec := range n.staged {
+		if from != "" && rec.tx.From != from {
+			continue
+		}
+		staged = append(staged, map[string]interface{}{
+			"hash":      rec.hash,
+			"from":      rec.tx.From,
+			"to":        rec.tx.To,
+			"amount":    client.FromAtoms(rec.amount),
+			"nonce":     rec.tx.Nonce,
+			"ou":        rec.tx.OU,
+			"timestamp": rec.tx.Timestamp,
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"count":               len(staged),
+		"staged_transactions": staged,
+	})
+}
+
+func (rec *record) detail() map[string]interface{} {
+	status := "pending"
+	if rec.epoch > 0 {
+		status = "confirmed"
+	}
+	parsed := map[string]interface{}{
+		"from":       rec.tx.From,
+		"to":         rec.tx.To,
+		"amount":     client.FromAtoms(rec.amount),
+		"amount_raw": rec.amount.String(),
+		"nonce":      rec.tx.Nonce,
+		"ou":         rec.tx.OU,
+		"timestamp":  rec.tx.Timestamp,
+	}
+	if rec.tx.Message != "" {
+		parsed["message"] = rec.tx.Message
+	}
+	detail := map[string]interface{}{
+		"tx_hash":    rec.hash,
+		"status":     status,
+		"parsed_tx":  parsed,
+		"signature":  rec.signature,
+		"public_key": rec.publicKey,
+	}
+	if rec.epoch > 0 {
+		detail["epoch"] = rec.epoch
+	}
+	return detail
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}